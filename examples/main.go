@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 
@@ -33,4 +34,12 @@ func main() {
 	for value := range data {
 		fmt.Printf("Element1: %v, type: %T\n", value, value)
 	}
+
+	fmt.Println("\nParsing with `gompjs.ParseAll` and options ...")
+	optionsInput := "[1] [] {a: 2} {}"
+	fmt.Printf("\nInput: %+v\n", optionsInput)
+	data, _ = gompjs.ParseAll(context.Background(), &optionsInput, gompjs.WithOmitEmpty(), gompjs.WithLoader(json.Unmarshal))
+	for value := range data {
+		fmt.Printf("Element: %v, type: %T\n", value, value)
+	}
 }
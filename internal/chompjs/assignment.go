@@ -0,0 +1,170 @@
+package chompjs
+
+import "strings"
+
+// ParsePath splits the member expression target, such as `window.App["config"].items`, into its properties.
+// It returns false if target is not a member expression.
+func ParsePath(target string) ([]string, bool) {
+	target = strings.TrimSpace(target)
+	path, next := readPath(target, 0)
+	return path, path != nil && next == len(target)
+}
+
+// FindAssignment returns the position of the object or array literal assigned to the member expression path in input,
+// such as `var data = {...}` or `window["data"] = [...]`. Assignments of other values are skipped.
+// It returns false if there's no such assignment.
+func FindAssignment(input string, path []string) (int, bool) {
+	return findAssignment(input, path, true)
+}
+
+// FindAssignedValue is FindAssignment for a value of any kind.
+func FindAssignedValue(input string, path []string) (int, bool) {
+	return findAssignment(input, path, false)
+}
+
+func findAssignment(input string, path []string, literal bool) (int, bool) {
+	for i := 0; i < len(input); {
+		switch c := input[i]; {
+		case c == '"' || c == '\'' || c == '`':
+			i = skipString(input, i)
+		case c == '/' && skipComment(input, i) > i:
+			i = skipComment(input, i)
+		case isIdentifierChar(c) && !isDigit(c):
+			// a property of another object, such as `other.data = {...}`, is not the one looked for
+			if j := skipSpacesBackwards(input, i); j > 0 && input[j-1] == '.' {
+				i = skipIdentifier(input, i)
+				continue
+			}
+			found, next := readPath(input, i)
+			if next <= i {
+				next = skipIdentifier(input, i)
+			}
+			if equalPaths(found, path) {
+				if value, ok := assignedValue(input, next, literal); ok {
+					return value, true
+				}
+			}
+			i = next
+		default:
+			i++
+		}
+	}
+	return 0, false
+}
+
+// assignedValue returns the position of the value assigned right after position i of input,
+// which must be an object or array literal if literal is set.
+func assignedValue(input string, i int, literal bool) (int, bool) {
+	i = skipSpaces(input, i)
+	if i >= len(input) || input[i] != '=' || strings.HasPrefix(input[i:], "==") || strings.HasPrefix(input[i:], "=>") {
+		return 0, false
+	}
+	i = skipBlank(input, i+1)
+	if i < len(input) && (!literal || input[i] == '{' || input[i] == '[') {
+		return i, true
+	}
+	return 0, false
+}
+
+// readPath reads the member expression starting at position i of input, made of identifiers, dotted properties
+// and properties in brackets, which are quoted strings or numbers. It returns the properties and the position right after it.
+func readPath(input string, i int) ([]string, int) {
+	end := skipIdentifier(input, i)
+	if end == i || isDigit(input[i]) {
+		return nil, i
+	}
+	path := []string{input[i:end]}
+	i = end
+	for {
+		j := skipSpaces(input, i)
+		if j >= len(input) {
+			return path, i
+		}
+		switch input[j] {
+		case '.':
+			start := skipSpaces(input, j+1)
+			end := skipIdentifier(input, start)
+			if end == start {
+				return path, i
+			}
+			path = append(path, input[start:end])
+			i = end
+		case '[':
+			start := skipSpaces(input, j+1)
+			var property string
+			end := start
+			switch {
+			case start < len(input) && (input[start] == '"' || input[start] == '\'' || input[start] == '`'):
+				end = skipString(input, start)
+				property = unquoteProperty(input[start:end])
+			default:
+				for end < len(input) && isDigit(input[end]) {
+					end++
+				}
+				property = input[start:end]
+			}
+			closing := skipSpaces(input, end)
+			if end == start || closing >= len(input) || input[closing] != ']' {
+				return path, i
+			}
+			path = append(path, property)
+			i = closing + 1
+		default:
+			return path, i
+		}
+	}
+}
+
+// unquoteProperty returns the contents of a quoted property name, escaped characters are taken as they are.
+func unquoteProperty(quoted string) string {
+	if len(quoted) < 2 || quoted[len(quoted)-1] != quoted[0] {
+		return quoted
+	}
+	var b strings.Builder
+	for i := 1; i < len(quoted)-1; i++ {
+		if quoted[i] == '\\' && i+1 < len(quoted)-1 {
+			i++
+		}
+		b.WriteByte(quoted[i])
+	}
+	return b.String()
+}
+
+func equalPaths(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// isIdentifierChar tells whether c may be a part of a JavaScript identifier, any non-ASCII byte is taken for a letter.
+func isIdentifierChar(c byte) bool {
+	return isAlnum(c) || c == '_' || c == '$' || c >= 0x80
+}
+
+func skipIdentifier(input string, i int) int {
+	for i < len(input) && isIdentifierChar(input[i]) {
+		i++
+	}
+	return i
+}
+
+func skipSpaces(input string, i int) int {
+	for i < len(input) && isSpace(input[i]) {
+		i++
+	}
+	return i
+}
+
+// skipSpacesBackwards returns the position right after the last non-space character before position i of input.
+func skipSpacesBackwards(input string, i int) int {
+	for i > 0 && isSpace(input[i-1]) {
+		i--
+	}
+	return i
+}
@@ -1,3 +1,5 @@
+//go:build cgo && !purego
+
 /*
  * Copyright 2020-2025 Mariusz Obajtek. All rights reserved.
  * License: https://github.com/Nykakin/chompjs/blob/master/LICENSE
@@ -0,0 +1,147 @@
+package chompjs
+
+import "strings"
+
+// Call is a call of a function found in the input.
+type Call struct {
+	// Callee is the function called.
+	Callee []string
+	// Start is the position the callee begins at.
+	Start int
+	// Args are the arguments of the call.
+	Args []Arg
+}
+
+// Arg is an argument of a call.
+type Arg struct {
+	// Before and After are the positions of the separators around the argument,
+	// i.e. either the opening parenthesis or a comma, and either a comma or the closing parenthesis.
+	Before, After int
+	// Start and End are the positions of the argument itself, without spaces and comments.
+	Start, End int
+	// Literal tells whether the argument is a single literal, i.e. an object, an array, a string, a number, a boolean or null.
+	Literal bool
+}
+
+// FindCalls returns every call of the functions callees in input, strings and comments are skipped.
+// A callee doesn't match the same property of another object, e.g. `dataLayer.push` doesn't match `window.dataLayer.push(...)`.
+func FindCalls(input string, callees [][]string) []Call {
+	var calls []Call
+	for i := 0; i < len(input); {
+		switch c := input[i]; {
+		case c == '"' || c == '\'' || c == '`':
+			i = skipString(input, i)
+		case c == '/' && skipComment(input, i) > i:
+			i = skipComment(input, i)
+		case isIdentifierChar(c) && !isDigit(c):
+			if j := skipSpacesBackwards(input, i); j > 0 && input[j-1] == '.' {
+				i = skipIdentifier(input, i)
+				continue
+			}
+			path, next := readPath(input, i)
+			if next <= i {
+				next = skipIdentifier(input, i)
+			}
+			if matchesAny(path, callees) && !isDeclaration(input, i) {
+				if args, _, ok := splitArgs(input, skipBlank(input, next)); ok {
+					calls = append(calls, Call{Callee: path, Start: i, Args: args})
+				}
+			}
+			// the arguments are scanned too, they may hold calls
+			i = next
+		default:
+			i++
+		}
+	}
+	return calls
+}
+
+func matchesAny(path []string, paths [][]string) bool {
+	for _, p := range paths {
+		if equalPaths(path, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// isDeclaration tells whether the identifier at position i of input is the name of a function declaration.
+func isDeclaration(input string, i int) bool {
+	i = skipSpacesBackwards(input, i)
+	return strings.HasSuffix(input[:i], "function") && (i == len("function") || !isIdentifierChar(input[i-len("function")-1]))
+}
+
+// splitArgs splits the arguments between the parenthesis at position open of input and the one closing it.
+// It also returns the position of the closing parenthesis, or false if there's no call at open.
+func splitArgs(input string, open int) ([]Arg, int, bool) {
+	if open >= len(input) || input[open] != '(' {
+		return nil, 0, false
+	}
+	close, ok := MatchingBracket(input, open)
+	if !ok {
+		return nil, 0, false
+	}
+	var args []Arg
+	before := open
+	for i := open + 1; i <= close; {
+		switch c := input[i]; {
+		case c == ',' || i == close:
+			arg := Arg{Before: before, After: i, Start: skipBlank(input, before+1), End: i}
+			for arg.End > arg.Start && isSpace(input[arg.End-1]) {
+				arg.End--
+			}
+			// a trailing comma doesn't make an argument
+			if arg.Start < arg.End || c == ',' {
+				arg.Literal = isLiteral(input[arg.Start:arg.End])
+				args = append(args, arg)
+			}
+			before = i
+			i++
+		case c == '(' || c == '[' || c == '{':
+			end, _ := MatchingBracket(input, i)
+			i = end + 1
+		case c == '"' || c == '\'' || c == '`':
+			i = skipString(input, i)
+		case c == '/' && skipComment(input, i) > i:
+			i = skipComment(input, i)
+		default:
+			i++
+		}
+	}
+	return args, close, true
+}
+
+// isLiteral tells whether arg is a single literal: an object, an array, a string, a number, a boolean or null.
+func isLiteral(arg string) bool {
+	if arg == "" {
+		return false
+	}
+	switch c := arg[0]; {
+	case c == '{' || c == '[':
+		end, ok := MatchingBracket(arg, 0)
+		return ok && end == len(arg)-1
+	case c == '"' || c == '\'' || c == '`':
+		return skipString(arg, 0) == len(arg) && (c != '`' || !strings.Contains(arg, "${"))
+	case isDigit(c) || c == '-' || c == '.':
+		return isNumber(arg)
+	}
+	return arg == "true" || arg == "false" || arg == "null"
+}
+
+// isNumber tells whether s is a number literal, such as -1.5e3, 0x1F or 1_000.
+func isNumber(s string) bool {
+	s = strings.TrimPrefix(s, "-")
+	if s == "" || s == "." {
+		return false
+	}
+	if !isDigit(s[0]) && s[0] != '.' {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !isAlnum(c) && c != '.' && c != '_' && !((c == '+' || c == '-') && i > 0 && toLower(s[i-1]) == 'e') {
+			return false
+		}
+	}
+	return true
+}
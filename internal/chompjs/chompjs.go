@@ -1,75 +1,287 @@
 package chompjs
 
-/*
-#cgo LDFLAGS: -lm
-
-#include <stdlib.h>
-#include "parser.h"
-struct Lexer lexer;
-
-*/
-import "C"
-import (
-	"fmt"
-	"unsafe"
+import "context"
+
+// lexerStatus mirrors LexerStatus (parser.h), both lexer backends report it.
+type lexerStatus int
+
+const (
+	canAdvance lexerStatus = iota
+	finished
+	failed
 )
 
+// FixString converts the first JavaScript object found in input into a valid JSON string.
+// Every call uses its own lexer, so it is safe to call from many goroutines at once.
+//
+// A lexer failure is returned as *Error, ErrNoObject is its kind if there's no object in input.
 func FixString(input *string) (*string, error) {
-	inputStr := C.CString(*input)
-	defer C.free(unsafe.Pointer(inputStr))
-	C.init_lexer(&C.lexer, inputStr)
-	for C.lexer.lexer_status == C.CAN_ADVANCE {
-		C.advance(&C.lexer)
-	}
-	parsedString := C.GoString(C.lexer.output.data)
-	C.release_lexer(&C.lexer)
-	if C.lexer.lexer_status == C.ERROR {
-		err := fmt.Errorf("error parsing input near character %d", uint64(C.lexer.input_position))
+	return FixStringAt(input, 0)
+}
+
+// FixStringAt is FixString looking for the object from position start of input on.
+// Positions of the errors refer to the whole input.
+func FixStringAt(input *string, start int) (*string, error) {
+	return fixFirst(*input, start, (*input)[start:])
+}
+
+// FixArgs converts the arguments between the parentheses at positions open and close of input into a JSON array.
+// Positions of the errors refer to the whole input.
+func FixArgs(input *string, open, close int) (*string, error) {
+	return fixFirst(*input, open, "["+(*input)[open+1:close]+"]")
+}
+
+// fixFirst converts the first object of part, which is input from position start on,
+// though its first and last characters may differ.
+func fixFirst(input string, start int, part string) (*string, error) {
+	lexer := newLexer(part)
+	defer lexer.release()
+	object, _ := nextObject(context.Background(), lexer)
+	// the position of part is only needed for errors
+	var o origin
+	if lexer.status() == failed {
+		o.advance(input[:start])
+		err, _ := lexerError(part, o, lexer.inputPosition(), object.failingStep)
 		return nil, err
 	}
+	parsedString := lexer.output()
+	if parsedString == "" {
+		o.advance(input[:start])
+		return nil, newError(ErrNoObject, part, o, len(part))
+	}
 	return &parsedString, nil
 }
 
-func FixStrings(input *string) (<-chan *string, <-chan error) {
-	dataChannel := make(chan *string)
-	// this channel is created but not actually being used, as the original code doesn't raise on errors
-	errChannel := make(chan error, 1)
+// Object is an object found in the input.
+type Object struct {
+	// JSON is the object converted into a valid JSON string.
+	JSON string
+	// Start is the byte offset of the object in the input.
+	Start int
+	// End is the byte offset right after the object.
+	End int
+	// Line and Column are the 1-based position of Start, the column is counted in characters.
+	Line, Column int
+	// Part is the index of the part of the input the object is found in, see FixPartsContext.
+	Part int
+	// Keys are the byte offsets of the keys of the object in the input, see KeyOffsets.
+	// They are only found with Options.KeyOffsets.
+	Keys []int
+}
 
-	inputStr := C.CString(*input)
+// Part is the part input[Start:End] of the input.
+type Part struct {
+	Start, End int
+}
+
+// Options tune FixStringsContext and FixReaderContext, the zero value behaves just as the original code does.
+type Options struct {
+	// ReportErrors sends every lexer failure into the error channel as *Error and resumes right after the broken object,
+	// instead of sending whatever the lexer has put out before failing.
+	ReportErrors bool
+	// KeyOffsets finds the offsets of the keys of every object, see Object.Keys.
+	KeyOffsets bool
+}
+
+// FixStrings converts every JavaScript object found in input into a valid JSON string
+// and sends them one by one, along with their positions, into the returned data channel.
+// Every call uses its own lexer, so it is safe to call from many goroutines at once.
+func FixStrings(input *string) (<-chan *Object, <-chan error) {
+	return FixStringsContext(context.Background(), input, Options{})
+}
+
+// FixStringsContext is FixStrings bound to ctx. Once ctx is done the lexer stops,
+// its memory is released, ctx.Err() is sent into the error channel and both channels are closed.
+func FixStringsContext(ctx context.Context, input *string, opts Options) (<-chan *Object, <-chan error) {
+	dataChannel := make(chan *Object)
+	errChannel := make(chan error, 1)
+	f := &fixer{ctx: ctx, opts: opts, dataChannel: dataChannel, errChannel: errChannel, reported: -1}
 
 	go func() {
 		defer close(dataChannel)
 		defer close(errChannel)
-		defer C.free(unsafe.Pointer(inputStr))
-
-		// json_iter_new (parser.h)
-		C.init_lexer(&C.lexer, inputStr)
+		if _, ok := f.fix(*input, true); !ok {
+			reportDone(ctx, errChannel)
+		}
+	}()
+	return dataChannel, errChannel
+}
 
-		// json_iter_dealloc (parser.h)
-		defer C.release_lexer(&C.lexer)
+// FixPartsContext is FixStringsContext lexing each of parts of input on its own, one after another,
+// so an object never spans two parts. Positions of the objects and errors refer to the whole input.
+// The parts must be ordered and must not overlap.
+func FixPartsContext(ctx context.Context, input *string, parts []Part, opts Options) (<-chan *Object, <-chan error) {
+	dataChannel := make(chan *Object)
+	errChannel := make(chan error, 1)
+	f := &fixer{ctx: ctx, opts: opts, dataChannel: dataChannel, errChannel: errChannel, reported: -1}
 
-		// json_iter_next (parser.h)
-		for {
-			for C.lexer.lexer_status == C.CAN_ADVANCE {
-				C.advance(&C.lexer)
-			}
-			if C.lexer.output.index == 1 {
+	go func() {
+		defer close(dataChannel)
+		defer close(errChannel)
+		for i, part := range parts {
+			f.origin.advance((*input)[f.origin.offset:part.Start])
+			f.part = i
+			f.brackets = ""
+			if _, ok := f.fix((*input)[part.Start:part.End], true); !ok {
+				reportDone(ctx, errChannel)
 				return
 			}
-			// THIS CODE, IF ENABLED, MAY CAUSE OR MAY NOT ERRORS!!!
-			// this is not from the original code ->
-			// if C.lexer.lexer_status == C.ERROR {
-			// 	err := fmt.Errorf("error parsing input near character %d", uint64(C.lexer.input_position))
-			// 	errChannel <- err
-			// 	return
-			// }
-			// <-
-			// writing correct data into the channel
-			parsedString := C.GoString(C.lexer.output.data)
-			dataChannel <- &parsedString
-			// from json_iter_next (parser.h)
-			C.reset_lexer_output(&C.lexer)
 		}
 	}()
 	return dataChannel, errChannel
 }
+
+// fixer sends the objects of its input, which may come in chunks or parts, into dataChannel.
+type fixer struct {
+	ctx         context.Context
+	opts        Options
+	dataChannel chan<- *Object
+	errChannel  chan<- error
+	// origin is the position of the input being lexed in the whole input.
+	origin origin
+	// reported is the offset of the last error reported, as an object is lexed again if it's split between chunks.
+	reported int
+	// part is the index of the part being lexed, see FixPartsContext.
+	part int
+	// brackets are the brackets open at the beginning of the input being lexed: the lexer doesn't close the brackets
+	// of an object it fails on, unless it reports errors, so they're carried into the chunk which follows.
+	brackets string
+	// before is the end of the input preceding the input being lexed, for the snippets of the errors.
+	before string
+}
+
+// fix lexes the objects of input just as json_iter_next (parser.h) does and sends them into the data channel.
+// Unless eof is set, an object which runs into the end of input is left unfinished.
+// It returns the amount of bytes of input which are done with, i.e. the beginning of the unfinished object,
+// and false if ctx is done.
+func (f *fixer) fix(input string, eof bool) (int, bool) {
+	// json_iter_new (parser.h)
+	lexer := newLexer(input)
+	// json_iter_dealloc (parser.h)
+	defer lexer.release()
+	lexer.setBrackets(f.brackets)
+	// brackets are the brackets open at the beginning of the object being lexed
+	brackets := f.brackets
+	cursor := newCursor(input)
+	for {
+		object, ok := nextObject(f.ctx, lexer)
+		if !ok {
+			return 0, false
+		}
+		if lexer.outputLen() == 1 {
+			f.brackets = brackets
+			return len(input), true
+		}
+		// either the end state or the error state has already moved past the last character of the object,
+		// and the lexer may look one character ahead of the closing bracket, so it must be in the input
+		if !eof && lexer.inputPosition() >= len(input) {
+			f.brackets = brackets
+			return object.start, true
+		}
+		if f.opts.ReportErrors && lexer.status() == failed {
+			err, offset := lexerError(input, f.origin, lexer.inputPosition(), object.failingStep)
+			// the snippet of the error must be in the input too
+			if !eof && offset+snippetRadius > len(input) {
+				f.brackets = brackets
+				return object.start, true
+			}
+			if f.before != "" {
+				err.Snippet = snippet(f.before+input, len(f.before)+offset)
+			}
+			if err.Offset != f.reported {
+				f.reported = err.Offset
+				if !sendError(f.ctx, f.errChannel, err) {
+					return 0, false
+				}
+			}
+			resume := skipObject(input, offset, lexer.depth())
+			if resume <= offset && offset < len(input) {
+				resume = offset + 1
+			}
+			if !eof && resume >= len(input) {
+				return object.start, true
+			}
+			lexer.restart(resume)
+			brackets = ""
+			continue
+		}
+		// both the end state and the error state move one character further,
+		// but parser.c may move past the end of the input when it fails on its last characters
+		end := lexer.inputPosition() - 1
+		if end > len(input) {
+			end = len(input)
+		}
+		parsed := &Object{
+			JSON:  lexer.output(),
+			Start: f.origin.offset + object.start,
+			End:   f.origin.offset + end,
+			Part:  f.part,
+		}
+		parsed.Line, parsed.Column = f.origin.shift(cursor.moveTo(object.start))
+		// the keys of an object the lexer fails on aren't the keys of its output
+		if f.opts.KeyOffsets && lexer.status() != failed {
+			parsed.Keys = KeyOffsets(input, object.start)
+			for i := range parsed.Keys {
+				parsed.Keys[i] += f.origin.offset
+			}
+		}
+		select {
+		case f.dataChannel <- parsed:
+		case <-f.ctx.Done():
+			return 0, false
+		}
+		lexer.reset()
+		brackets = lexer.brackets()
+	}
+}
+
+// sendError sends err into errChannel unless ctx is done first, in which case it returns false.
+func sendError(ctx context.Context, errChannel chan<- error, err error) bool {
+	select {
+	case errChannel <- err:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// reportDone sends ctx.Err() into errChannel unless there is an unread error in it already.
+func reportDone(ctx context.Context, errChannel chan<- error) {
+	select {
+	case errChannel <- ctx.Err():
+	default:
+	}
+}
+
+// object describes the input an object has been lexed from.
+type object struct {
+	// start is the position the object begins at.
+	start int
+	// failingStep is the position the lexer step which has switched the lexer into the error state started at.
+	failingStep int
+}
+
+// nextObject advances the lexer until the current object is either finished or failed.
+// It returns false if ctx is done.
+func nextObject(ctx context.Context, lexer *lexer) (object, bool) {
+	done := ctx.Done()
+	obj := object{start: -1}
+	for lexer.status() == canAdvance {
+		select {
+		case <-done:
+			return obj, false
+		default:
+		}
+		step := lexer.inputPosition()
+		lexer.advance()
+		// the first step is the begin state, which stops right at the opening bracket
+		if obj.start < 0 {
+			obj.start = lexer.inputPosition()
+		}
+		// the step switching into the error state is followed by the one running it
+		if lexer.status() == canAdvance {
+			obj.failingStep = step
+		}
+	}
+	return obj, true
+}
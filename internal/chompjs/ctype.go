@@ -0,0 +1,24 @@
+package chompjs
+
+// isSpace mirrors isspace (ctype.h) in the C locale.
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\v' || c == '\f' || c == '\r'
+}
+
+// isDigit mirrors isdigit (ctype.h) in the C locale.
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// isAlnum mirrors isalnum (ctype.h) in the C locale.
+func isAlnum(c byte) bool {
+	return isDigit(c) || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// toLower mirrors tolower (ctype.h) in the C locale.
+func toLower(c byte) byte {
+	if c >= 'A' && c <= 'Z' {
+		return c + 'a' - 'A'
+	}
+	return c
+}
@@ -0,0 +1,83 @@
+package chompjs
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Kinds of lexer errors.
+var (
+	ErrNoObject                 = errors.New("no object found")
+	ErrUnexpectedEOF            = errors.New("premature end of input")
+	ErrUnterminatedString       = errors.New("unterminated string")
+	ErrUnexpectedClosingBracket = errors.New("unexpected closing bracket")
+	ErrInvalidNumber            = errors.New("invalid number")
+)
+
+// Error reports why and where the lexer has failed.
+type Error struct {
+	// Kind is one of the kinds of lexer errors.
+	Kind error
+	// Offset is the byte offset of the input the lexer has failed at.
+	Offset int
+	// Line and Column are the 1-based position of Offset, the column is counted in characters.
+	Line, Column int
+	// Snippet is the input around Offset.
+	Snippet string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("error parsing input near character %d: %v", e.Offset, e.Kind)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
+
+// newError returns the error of kind found at offset of input, which starts at o.
+func newError(kind error, input string, o origin, offset int) *Error {
+	line, column := o.locate(input, offset)
+	return &Error{
+		Kind:    kind,
+		Offset:  o.offset + offset,
+		Line:    line,
+		Column:  column,
+		Snippet: snippet(input, offset),
+	}
+}
+
+// lexerError tells why the lexer has failed. The lexer has stopped at position,
+// and failingStep is the position the step which has switched it into the error state started at.
+// It also returns the offset of input the lexer has failed at.
+//
+// The C lexer reports nothing but the error state, so the reason is worked out from the input:
+// only json() fails in the middle of the input, on '>' and ')', and only handle_numeric() fails on other characters,
+// while at the end of the input it's handle_quoted() which fails on unterminated strings.
+func lexerError(input string, o origin, position, failingStep int) (*Error, int) {
+	// error() has moved past the character the lexer has failed at
+	offset := position - 1
+	if offset > len(input) {
+		offset = len(input)
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	var kind error
+	atEnd := offset == len(input) || input[offset] == 0
+	switch {
+	case !atEnd && (input[offset] == '>' || input[offset] == ')'):
+		kind = ErrUnexpectedClosingBracket
+	case !atEnd:
+		kind = ErrInvalidNumber
+	default:
+		for failingStep < len(input) && isSpace(input[failingStep]) {
+			failingStep++
+		}
+		if failingStep < len(input) && (input[failingStep] == '"' || input[failingStep] == '\'' || input[failingStep] == '`') {
+			kind = ErrUnterminatedString
+		} else {
+			kind = ErrUnexpectedEOF
+		}
+	}
+	return newError(kind, input, o, offset), offset
+}
@@ -0,0 +1,192 @@
+package chompjs
+
+import "strings"
+
+// IIFE is an immediately invoked function returning an object literal,
+// such as `(function(a, b){return {x: a, y: [b]}}(1, "z"))`.
+type IIFE struct {
+	// Params are the names of the parameters of the function.
+	Params []string
+	// Start and End are the positions of the object or array literal returned.
+	Start, End int
+	// Args are the arguments the function is called with.
+	Args []Arg
+}
+
+// ParseIIFE parses the immediately invoked function starting at position start of input,
+// either `(function(...){...}(...))` or `(function(...){...})(...)`.
+// Statements preceding the return statement of the function are ignored.
+func ParseIIFE(input string, start int) (IIFE, bool) {
+	var iife IIFE
+	i := skipBlank(input, start)
+	parens := 0
+	for i < len(input) && input[i] == '(' {
+		parens++
+		i = skipBlank(input, i+1)
+	}
+	if !strings.HasPrefix(input[i:], "function") {
+		return iife, false
+	}
+	i = skipBlank(input, i+len("function"))
+	// the function may be named
+	i = skipBlank(input, skipIdentifier(input, i))
+	if i >= len(input) || input[i] != '(' {
+		return iife, false
+	}
+	params, close, ok := splitArgs(input, i)
+	if !ok {
+		return iife, false
+	}
+	for _, param := range params {
+		name := input[param.Start:param.End]
+		if name == "" || skipIdentifier(name, 0) != len(name) {
+			return iife, false
+		}
+		iife.Params = append(iife.Params, name)
+	}
+	i = skipBlank(input, close+1)
+	if i >= len(input) || input[i] != '{' {
+		return iife, false
+	}
+	bodyEnd, ok := MatchingBracket(input, i)
+	if !ok {
+		return iife, false
+	}
+	if iife.Start, ok = findReturn(input, i+1, bodyEnd); !ok {
+		return iife, false
+	}
+	if iife.End, ok = MatchingBracket(input, iife.Start); !ok || iife.End >= bodyEnd {
+		return iife, false
+	}
+	iife.End++
+	i = skipBlank(input, bodyEnd+1)
+	// `(function(){...})(...)` closes a parenthesis before the call
+	if i < len(input) && input[i] == ')' && parens > 0 {
+		i = skipBlank(input, i+1)
+	}
+	if iife.Args, _, ok = splitArgs(input, i); !ok {
+		return iife, false
+	}
+	return iife, true
+}
+
+// findReturn returns the position of the object or array literal returned by the statements of input[start:end],
+// the bodies of nested functions are skipped.
+func findReturn(input string, start, end int) (int, bool) {
+	for i := start; i < end; {
+		switch c := input[i]; {
+		case c == '"' || c == '\'' || c == '`':
+			i = skipString(input, i)
+		case c == '/' && skipComment(input, i) > i:
+			i = skipComment(input, i)
+		case c == '(' || c == '[' || c == '{':
+			close, ok := MatchingBracket(input, i)
+			if !ok {
+				return 0, false
+			}
+			i = close + 1
+		case isIdentifierChar(c):
+			next := skipIdentifier(input, i)
+			if input[i:next] == "return" && (i == 0 || !isIdentifierChar(input[i-1])) {
+				value := skipBlank(input, next)
+				if value < end && (input[value] == '{' || input[value] == '[') {
+					return value, true
+				}
+				return 0, false
+			}
+			i = next
+		default:
+			i++
+		}
+	}
+	return 0, false
+}
+
+// SubstituteIdentifiers replaces the identifiers of the JavaScript expression s, which are used as values, with their values.
+// Property names, such as the keys of objects and the properties following a dot, are kept, as well as strings, comments
+// and nested functions. A shorthand property, such as `{a}`, keeps its key, so it becomes `{a: value}`.
+func SubstituteIdentifiers(s string, values map[string]string) string {
+	var b strings.Builder
+	written := 0
+	// nesting holds the brackets the position is in
+	var nesting []byte
+	for i := 0; i < len(s); {
+		switch c := s[i]; {
+		case c == '"' || c == '\'' || c == '`':
+			i = skipString(s, i)
+		case c == '/' && skipComment(s, i) > i:
+			i = skipComment(s, i)
+		case c == '{' || c == '[' || c == '(':
+			nesting = append(nesting, c)
+			i++
+		case c == '}' || c == ']' || c == ')':
+			if len(nesting) > 0 {
+				nesting = nesting[:len(nesting)-1]
+			}
+			i++
+		case isIdentifierChar(c) && !isDigit(c):
+			next := skipIdentifier(s, i)
+			// nested functions have parameters of their own
+			if s[i:next] == "function" {
+				i = skipFunction(s, next)
+				continue
+			}
+			value, ok := values[s[i:next]]
+			previous := skipSpacesBackwards(s, i)
+			following := skipBlank(s, next)
+			// an identifier following the opening brace or a comma of an object, or followed by a colon, is a key
+			inObject := len(nesting) > 0 && nesting[len(nesting)-1] == '{'
+			key := inObject && previous > 0 && (s[previous-1] == '{' || s[previous-1] == ',')
+			switch {
+			case !ok || (previous > 0 && s[previous-1] == '.'):
+			case !key && !(following < len(s) && s[following] == ':'):
+				b.WriteString(s[written:i])
+				writeValue(&b, value)
+				written = next
+			case following < len(s) && (s[following] == ',' || s[following] == '}'):
+				b.WriteString(s[written:next])
+				b.WriteString(": ")
+				writeValue(&b, value)
+				written = next
+			}
+			i = next
+		case isDigit(c):
+			// numbers such as 1e5 aren't identifiers
+			i = skipIdentifier(s, i)
+		default:
+			i++
+		}
+	}
+	if written == 0 {
+		return s
+	}
+	b.WriteString(s[written:])
+	return b.String()
+}
+
+// writeValue writes the value of an identifier followed by a space: the lexer looks two characters past true, false
+// and null to tell them from identifiers, so in `{x:true,y:1}` it would take true for an identifier.
+func writeValue(b *strings.Builder, value string) {
+	b.WriteString(value)
+	b.WriteByte(' ')
+}
+
+// skipFunction returns the position right after the body of the function, whose parameters follow position i of s.
+func skipFunction(s string, i int) int {
+	i = skipBlank(s, skipIdentifier(s, skipBlank(s, i)))
+	if i >= len(s) || s[i] != '(' {
+		return i
+	}
+	close, ok := MatchingBracket(s, i)
+	if !ok {
+		return len(s)
+	}
+	i = skipBlank(s, close+1)
+	if i >= len(s) || s[i] != '{' {
+		return i
+	}
+	if close, ok = MatchingBracket(s, i); !ok {
+		return len(s)
+	}
+	return close + 1
+}
@@ -0,0 +1,61 @@
+package chompjs
+
+// ParseJSONP checks that input is a JSONP response, i.e. a single call of the callback, such as `cb({...});`,
+// optionally preceded by comments and a guard, such as `/**/ cb && cb([...])` or `typeof cb === 'function' && cb(...)`.
+// It returns the callback and the positions of the parentheses around the arguments of the call.
+func ParseJSONP(input string) (callback []string, open, close int, ok bool) {
+	i := skipBlank(input, 0)
+	if j, guarded := skipGuard(input, i); guarded {
+		i = j
+	}
+	callback, i = readPath(input, i)
+	if callback == nil {
+		return nil, 0, 0, false
+	}
+	open = skipBlank(input, i)
+	if open >= len(input) || input[open] != '(' {
+		return nil, 0, 0, false
+	}
+	if close, ok = MatchingBracket(input, open); !ok {
+		return nil, 0, 0, false
+	}
+	i = skipBlank(input, close+1)
+	if i < len(input) && input[i] == ';' {
+		i = skipBlank(input, i+1)
+	}
+	if i < len(input) {
+		return nil, 0, 0, false
+	}
+	return callback, open, close, true
+}
+
+// skipGuard returns the position right after the guard of a JSONP callback starting at position i of input,
+// which is either `cb &&` or `typeof cb === 'function' &&`.
+func skipGuard(input string, i int) (int, bool) {
+	path, next := readPath(input, i)
+	if path == nil {
+		return i, false
+	}
+	if len(path) == 1 && path[0] == "typeof" {
+		if path, next = readPath(input, skipBlank(input, next)); path == nil {
+			return i, false
+		}
+		next = skipBlank(input, next)
+		for _, operator := range []string{"===", "=="} {
+			if len(input)-next >= len(operator) && input[next:next+len(operator)] == operator {
+				next += len(operator)
+				break
+			}
+		}
+		next = skipBlank(input, next)
+		if next >= len(input) || (input[next] != '"' && input[next] != '\'') {
+			return i, false
+		}
+		next = skipString(input, next)
+	}
+	next = skipBlank(input, next)
+	if len(input)-next < 2 || input[next:next+2] != "&&" {
+		return i, false
+	}
+	return skipBlank(input, next+2), true
+}
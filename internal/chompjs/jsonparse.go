@@ -0,0 +1,184 @@
+package chompjs
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// UnwrapJSONParse replaces every `JSON.parse(<string literal>)` call of input with the contents of the string literal,
+// so the lexer sees the document passed to JSON.parse instead of a string. Calls whose argument isn't a single string literal
+// are left as they are, as well as the ones found in strings and comments.
+func UnwrapJSONParse(input string) string {
+	if !strings.Contains(input, "JSON") {
+		return input
+	}
+	var b strings.Builder
+	written := 0
+	for i := 0; i < len(input); {
+		switch c := input[i]; {
+		case c == '"' || c == '\'' || c == '`':
+			i = skipString(input, i)
+		case c == '/' && skipComment(input, i) > i:
+			i = skipComment(input, i)
+		case isIdentifierChar(c) && !isDigit(c):
+			if j := skipSpacesBackwards(input, i); j > 0 && input[j-1] == '.' {
+				i = skipIdentifier(input, i)
+				continue
+			}
+			path, next := readPath(input, i)
+			if next <= i {
+				next = skipIdentifier(input, i)
+			}
+			if equalPaths(path, []string{"JSON", "parse"}) {
+				if document, end, ok := jsonParseArgument(input, next); ok {
+					b.WriteString(input[written:i])
+					b.WriteString(document)
+					written = end
+					next = end
+				}
+			}
+			i = next
+		default:
+			i++
+		}
+	}
+	if written == 0 {
+		return input
+	}
+	b.WriteString(input[written:])
+	return b.String()
+}
+
+// jsonParseArgument reads `(<string literal>)` starting at position i of input.
+// It returns the string and the position right after the closing parenthesis.
+func jsonParseArgument(input string, i int) (string, int, bool) {
+	i = skipSpaces(input, i)
+	if i >= len(input) || input[i] != '(' {
+		return "", 0, false
+	}
+	start := skipSpaces(input, i+1)
+	if start >= len(input) || (input[start] != '"' && input[start] != '\'' && input[start] != '`') {
+		return "", 0, false
+	}
+	end := skipString(input, start)
+	closing := skipSpaces(input, end)
+	if closing >= len(input) || input[closing] != ')' {
+		return "", 0, false
+	}
+	document, ok := UnquoteJS(input[start:end])
+	if !ok {
+		return "", 0, false
+	}
+	return document, closing + 1, true
+}
+
+// UnquoteJS returns the value of the JavaScript string literal quoted, which is quoted with ', " or `.
+// It returns false if quoted isn't a valid literal, a template literal mustn't hold substitutions.
+func UnquoteJS(quoted string) (string, bool) {
+	if len(quoted) < 2 || quoted[len(quoted)-1] != quoted[0] {
+		return "", false
+	}
+	quote := quoted[0]
+	s := quoted[1 : len(quoted)-1]
+	if quote == '`' && strings.Contains(s, "${") {
+		return "", false
+	}
+	if !strings.ContainsRune(s, '\\') {
+		return s, true
+	}
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		if i >= len(s) {
+			return "", false
+		}
+		switch c := s[i]; c {
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		case 'b':
+			b.WriteByte('\b')
+		case 'f':
+			b.WriteByte('\f')
+		case 'v':
+			b.WriteByte('\v')
+		case '0':
+			b.WriteByte(0)
+		case '\r':
+			// line continuation
+			if i+1 < len(s) && s[i+1] == '\n' {
+				i++
+			}
+		case '\n':
+			// line continuation
+		case 'x':
+			if i+3 > len(s) {
+				return "", false
+			}
+			value, err := strconv.ParseUint(s[i+1:i+3], 16, 8)
+			if err != nil {
+				return "", false
+			}
+			b.WriteRune(rune(value))
+			i += 2
+		case 'u':
+			r, next, ok := readUnicodeEscape(s, i+1)
+			if !ok {
+				return "", false
+			}
+			i = next - 1
+			// a surrogate pair is written as two escapes
+			if utf16.IsSurrogate(r) && strings.HasPrefix(s[next:], "\\u") {
+				if low, after, ok := readUnicodeEscape(s, next+2); ok {
+					if pair := utf16.DecodeRune(r, low); pair != utf8.RuneError {
+						r = pair
+						i = after - 1
+					}
+				}
+			}
+			b.WriteRune(r)
+		default:
+			// U+2028 and U+2029 are line continuations too, any other character escapes itself
+			if strings.HasPrefix(s[i:], "\u2028") || strings.HasPrefix(s[i:], "\u2029") {
+				i += len("\u2028") - 1
+				continue
+			}
+			b.WriteByte(c)
+		}
+	}
+	return b.String(), true
+}
+
+// readUnicodeEscape reads either XXXX or {X...} starting at position i of s, as found after \u.
+// It returns the character and the position right after the escape.
+func readUnicodeEscape(s string, i int) (rune, int, bool) {
+	var digits string
+	end := i + 4
+	if strings.HasPrefix(s[i:], "{") {
+		closing := strings.IndexByte(s[i:], '}')
+		if closing < 0 {
+			return 0, 0, false
+		}
+		digits, end = s[i+1:i+closing], i+closing+1
+	} else if end <= len(s) {
+		digits = s[i:end]
+	}
+	if digits == "" {
+		return 0, 0, false
+	}
+	value, err := strconv.ParseUint(digits, 16, 32)
+	if err != nil || value > utf8.MaxRune {
+		return 0, 0, false
+	}
+	return rune(value), end, true
+}
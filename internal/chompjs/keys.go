@@ -0,0 +1,92 @@
+package chompjs
+
+// KeyOffsets returns the byte offsets of the keys of the object starting at position start of input,
+// nested objects included, in the order the lexer puts them out. The opening bracket is expected at start,
+// a parenthesis opens an array just as FixArgs lexes it.
+//
+// Keys are found the way the lexer finds them, so the n-th key of the JSON string the lexer puts out
+// is found at the n-th offset, even for values the lexer turns into strings, such as functions.
+func KeyOffsets(input string, start int) []int {
+	var offsets []int
+	var nesting []byte
+	isKey := false
+	for i := start; i < len(input); {
+		switch c := input[i]; c {
+		case ' ', '\t', '\n', '\r', '\v', '\f':
+			i++
+		case '{', '[', '(':
+			if c == '(' {
+				c = '['
+			}
+			nesting = append(nesting, c)
+			isKey = c == '{'
+			i++
+		case '}', ']', ')':
+			if len(nesting) > 0 {
+				nesting = nesting[:len(nesting)-1]
+			}
+			if len(nesting) == 0 {
+				return offsets
+			}
+			isKey = nesting[len(nesting)-1] == '{'
+			i++
+		case ':':
+			isKey = false
+			i++
+		case ',':
+			isKey = len(nesting) > 0 && nesting[len(nesting)-1] == '{'
+			i++
+		default:
+			if j := skipComment(input, i); j > i {
+				i = j
+				continue
+			}
+			if isKey {
+				offsets = append(offsets, i)
+			}
+			var next int
+			if c == '"' || c == '\'' || c == '`' {
+				next = skipString(input, i)
+			} else {
+				next = skipUnrecognized(input, i)
+			}
+			// a closing bracket the lexer doesn't expect is part of the value
+			if next <= i {
+				next = i + 1
+			}
+			i = next
+		}
+	}
+	return offsets
+}
+
+// skipUnrecognized returns the position of the character ending the value at position of input,
+// just as handle_unrecognized (parser.c) finds it.
+func skipUnrecognized(input string, position int) int {
+	depth := 0
+	var quotedWith byte
+	for i := position; i < len(input); i++ {
+		switch c := input[i]; c {
+		case '\'', '"', '`':
+			if quotedWith == 0 {
+				quotedWith = c
+			} else if quotedWith == c {
+				quotedWith = 0
+			}
+		case '{', '[', '<', '(':
+			depth++
+		case '}', ']', '>', ')':
+			if depth == 0 {
+				return i
+			}
+			if quotedWith == 0 {
+				depth--
+			}
+		case ',', ':':
+			if quotedWith == 0 && depth <= 0 {
+				return i
+			}
+		}
+	}
+	return len(input)
+}
@@ -0,0 +1,97 @@
+//go:build cgo && !purego
+
+package chompjs
+
+/*
+#cgo LDFLAGS: -lm
+
+#include <stdlib.h>
+#include "parser.h"
+
+*/
+import "C"
+import "unsafe"
+
+// lexer wraps the original C state machine (parser.c).
+// Every lexer owns its C memory, so lexers can be used from different goroutines at once.
+type lexer struct {
+	input *C.char
+	c     C.struct_Lexer
+}
+
+func newLexer(input string) *lexer {
+	l := &lexer{input: C.CString(input)}
+	C.init_lexer(&l.c, l.input)
+	return l
+}
+
+func (l *lexer) advance() {
+	C.advance(&l.c)
+}
+
+func (l *lexer) status() lexerStatus {
+	switch l.c.lexer_status {
+	case C.CAN_ADVANCE:
+		return canAdvance
+	case C.FINISHED:
+		return finished
+	}
+	return failed
+}
+
+func (l *lexer) output() string {
+	return C.GoString(l.c.output.data)
+}
+
+func (l *lexer) outputLen() int {
+	return int(l.c.output.index)
+}
+
+func (l *lexer) inputPosition() int {
+	return int(l.c.input_position)
+}
+
+func (l *lexer) reset() {
+	C.reset_lexer_output(&l.c)
+}
+
+// depth returns the amount of brackets open at the current position.
+func (l *lexer) depth() int {
+	return int(l.c.nesting_depth.index)
+}
+
+// brackets returns the brackets open at the current position, the innermost one last.
+func (l *lexer) brackets() string {
+	return C.GoStringN(l.c.nesting_depth.data, C.int(l.c.nesting_depth.index))
+}
+
+// setBrackets makes brackets the brackets open, as if the lexer had lexed them.
+func (l *lexer) setBrackets(brackets string) {
+	C.clear(&l.c.nesting_depth)
+	if brackets == "" {
+		return
+	}
+	// check_capacity (buffer.c) only doubles the buffer once
+	length := C.size_t(len(brackets))
+	for length >= l.c.nesting_depth.memory_buffer_length {
+		C.check_capacity(&l.c.nesting_depth, length)
+	}
+	data := C.CString(brackets)
+	defer C.free(unsafe.Pointer(data))
+	C.push_string(&l.c.nesting_depth, data, length)
+}
+
+// restart resets the lexer to lex the input from position on, as if it was a new lexer.
+func (l *lexer) restart(position int) {
+	C.reset_lexer_output(&l.c)
+	C.clear(&l.c.nesting_depth)
+	l.c.unrecognized_nesting_depth = 0
+	l.c.input_position = C.size_t(position)
+}
+
+// release frees both lexer buffers and the input, release_lexer (parser.h) only frees the output buffer.
+func (l *lexer) release() {
+	C.release_lexer(&l.c)
+	C.release_char_buffer(&l.c.nesting_depth)
+	C.free(unsafe.Pointer(l.input))
+}
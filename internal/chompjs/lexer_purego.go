@@ -0,0 +1,541 @@
+//go:build !cgo || purego
+
+package chompjs
+
+import (
+	"bytes"
+	"math/big"
+)
+
+// state is an index of the internal state machine state, see enum StateIndex (parser.c).
+type state int
+
+const (
+	beginState state = iota
+	jsonState
+	valueState
+	endState
+	errorState
+)
+
+// lexer is a Go port of the original C state machine (parser.c).
+// It is used whenever cgo is not available or the purego build tag is set,
+// and must produce exactly the same output as the C code does.
+//
+// Reading past the end of the input always returns '\0', just as the C code
+// sees the terminating null character.
+type lexer struct {
+	input                    []byte
+	out                      []byte
+	inputPos                 int
+	lexerStatus              lexerStatus
+	state                    state
+	nestingDepth             []byte
+	unrecognizedNestingDepth int
+	isKey                    bool
+}
+
+// initialNestingDepth mirrors INITIAL_NESTING_DEPTH (parser.c).
+const initialNestingDepth = 20
+
+func newLexer(input string) *lexer {
+	return &lexer{
+		input: []byte(input),
+		// allocate in advance more memory for output than for input because we might need
+		// to add extra characters
+		// for example `{a: undefined}` will be translated as `{"a": "undefined"}`
+		out:          make([]byte, 0, 2*len(input)+1),
+		nestingDepth: make([]byte, 0, initialNestingDepth),
+		lexerStatus:  canAdvance,
+		state:        beginState,
+	}
+}
+
+func (l *lexer) advance() {
+	switch l.state {
+	case beginState:
+		l.state = l.begin()
+	case jsonState:
+		l.state = l.json()
+	case valueState:
+		l.state = l.value()
+	case endState:
+		l.state = l.end()
+	default:
+		l.state = l.error()
+	}
+}
+
+func (l *lexer) status() lexerStatus {
+	return l.lexerStatus
+}
+
+// output returns the output buffer up to the terminating null character, as C.GoString does.
+func (l *lexer) output() string {
+	if i := bytes.IndexByte(l.out, 0); i >= 0 {
+		return string(l.out[:i])
+	}
+	return string(l.out)
+}
+
+func (l *lexer) outputLen() int {
+	return len(l.out)
+}
+
+func (l *lexer) inputPosition() int {
+	return l.inputPos
+}
+
+func (l *lexer) reset() {
+	l.out = l.out[:0]
+	l.lexerStatus = canAdvance
+	l.state = beginState
+	l.isKey = false
+	l.inputPos--
+}
+
+// depth returns the amount of brackets open at the current position.
+func (l *lexer) depth() int {
+	return len(l.nestingDepth)
+}
+
+// brackets returns the brackets open at the current position, the innermost one last.
+func (l *lexer) brackets() string {
+	return string(l.nestingDepth)
+}
+
+// setBrackets makes brackets the brackets open, as if the lexer had lexed them.
+func (l *lexer) setBrackets(brackets string) {
+	l.nestingDepth = append(l.nestingDepth[:0], brackets...)
+}
+
+// restart resets the lexer to lex the input from position on, as if it was a new lexer.
+func (l *lexer) restart(position int) {
+	l.reset()
+	l.nestingDepth = l.nestingDepth[:0]
+	l.unrecognizedNestingDepth = 0
+	l.inputPos = position
+}
+
+func (l *lexer) release() {
+	l.input = nil
+	l.out = nil
+	l.nestingDepth = nil
+}
+
+// char returns the input character at position i or '\0' past the end of the input.
+func (l *lexer) char(i int) byte {
+	if i < 0 || i >= len(l.input) {
+		return 0
+	}
+	return l.input[i]
+}
+
+// hasPrefix mirrors strncmp(position, prefix, len(prefix)) == 0.
+func (l *lexer) hasPrefix(prefix string) bool {
+	if l.inputPos >= len(l.input) {
+		return false
+	}
+	return bytes.HasPrefix(l.input[l.inputPos:], []byte(prefix))
+}
+
+// nextChar gets next char, ignores whitespaces.
+func (l *lexer) nextChar() byte {
+	for isSpace(l.char(l.inputPos)) {
+		l.inputPos++
+	}
+	return l.char(l.inputPos)
+}
+
+// lastChar gets previously handled char.
+func (l *lexer) lastChar() byte {
+	return top(l.out)
+}
+
+// emit sends character to output buffer, advances input position.
+func (l *lexer) emit(c byte) {
+	l.out = append(l.out, c)
+	l.inputPos++
+}
+
+// emitInPlace sends character to output buffer, keeps old input position.
+func (l *lexer) emitInPlace(c byte) {
+	l.out = append(l.out, c)
+}
+
+// unemit removes last character from output buffer.
+func (l *lexer) unemit() {
+	if len(l.out) > 0 {
+		l.out = l.out[:len(l.out)-1]
+	}
+}
+
+// emitString sends string to output buffer, advances input position.
+func (l *lexer) emitString(s string) {
+	l.out = append(l.out, s...)
+	l.inputPos += len(s)
+}
+
+// emitStringInPlace sends string to output buffer, keeps old input position.
+func (l *lexer) emitStringInPlace(s string) {
+	l.out = append(l.out, s...)
+}
+
+func (l *lexer) begin() state {
+	// Ignoring characters until either '{' or '[' appears
+	for {
+		switch l.nextChar() {
+		case '{':
+			l.isKey = true
+			return jsonState
+		case '[':
+			return jsonState
+		case 0:
+			return endState
+		default:
+			l.inputPos++
+		}
+	}
+}
+
+func (l *lexer) json() state {
+	for {
+		switch c := l.nextChar(); c {
+		case '{', '[':
+			l.nestingDepth = append(l.nestingDepth, c)
+			if c == '{' {
+				l.isKey = true
+			}
+			l.emit(c)
+		case '}', ']':
+			if l.lastChar() == ',' {
+				l.unemit()
+			}
+			if len(l.nestingDepth) > 0 {
+				l.nestingDepth = l.nestingDepth[:len(l.nestingDepth)-1]
+			}
+			l.isKey = top(l.nestingDepth) == '{'
+			l.emit(c)
+			if len(l.nestingDepth) <= 0 {
+				return endState
+			}
+		case ':':
+			l.isKey = false
+			l.emit(':')
+		case ',':
+			l.emit(',')
+			l.isKey = top(l.nestingDepth) == '{'
+		case '/':
+			nextC := l.char(l.inputPos + 1)
+			if nextC == '/' || nextC == '*' {
+				l.handleComments()
+			} else {
+				return valueState
+			}
+		// This should never happen, but an malformed input can
+		// cause an infinite loop without this check
+		case '>', ')':
+			return errorState
+		default:
+			return valueState
+		}
+	}
+}
+
+func (l *lexer) handleString(s string) state {
+	nextChar := l.char(l.inputPos + len(s) + 1)
+	if nextChar == '_' || isAlnum(nextChar) {
+		return l.handleUnrecognized()
+	}
+	l.emitString(s)
+	return jsonState
+}
+
+// isInfinity tells whether Infinity is at the current position, followed by anything but an identifier character.
+func (l *lexer) isInfinity() bool {
+	if !l.hasPrefix("Infinity") {
+		return false
+	}
+	nextChar := l.char(l.inputPos + len("Infinity"))
+	return nextChar != '_' && !isAlnum(nextChar)
+}
+
+func (l *lexer) value() state {
+	c := l.nextChar()
+	switch {
+	case c == '"' || c == '\'' || c == '`':
+		return l.handleQuoted()
+	case isDigit(c) || c == '.' || c == '-':
+		if l.isKey {
+			return l.handleUnrecognized()
+		}
+		return l.handleNumeric()
+	case l.hasPrefix("true"):
+		return l.handleString("true")
+	case l.hasPrefix("false"):
+		return l.handleString("false")
+	case l.hasPrefix("null"):
+		return l.handleString("null")
+	case c == ']' || c == '}' || c == '[' || c == '{':
+		return jsonState
+	case l.hasPrefix("NaN"):
+		return l.handleString("NaN")
+	case !l.isKey && l.isInfinity():
+		l.emitString("Infinity")
+		return jsonState
+	}
+	return l.handleUnrecognized()
+}
+
+func (l *lexer) end() state {
+	l.emit(0)
+	l.lexerStatus = finished
+	return l.state
+}
+
+func (l *lexer) error() state {
+	l.emit(0)
+	l.lexerStatus = failed
+	return l.state
+}
+
+func (l *lexer) handleQuoted() state {
+	currentQuotation := l.nextChar()
+	l.emit('"')
+
+	for {
+		c := l.char(l.inputPos)
+		// handle escape sequences such as \\ and \'
+		if c == '\\' {
+			escaped := l.char(l.inputPos + 1)
+			if escaped == '\'' {
+				l.emit('\'')
+				l.inputPos++
+			} else {
+				l.emit('\\')
+				l.emit(escaped)
+			}
+			continue
+		}
+		// in case of malformed quotation we can reach end of the input
+		if c == 0 {
+			return errorState
+		}
+		// if we're closing the quotations, we're done with the string
+		if c == currentQuotation {
+			l.emit('"')
+			return jsonState
+		}
+		// otherwise, emit character
+		if c == '"' {
+			l.emitStringInPlace("\\\"")
+			l.inputPos++
+		} else {
+			l.emit(c)
+		}
+	}
+}
+
+func (l *lexer) handleNumeric() state {
+	c := l.nextChar()
+	switch {
+	case c >= '1' && c <= '9':
+		return l.handleNumericStandardBase()
+	case c == '.':
+		l.emitInPlace('0')
+		l.emit('.')
+		return l.handleNumericStandardBase()
+	case c == '-':
+		l.emit('-')
+		return l.handleNumeric()
+	case c == '0':
+		nc := toLower(l.char(l.inputPos + 1))
+		switch {
+		case nc == '.':
+			l.emit('0')
+			l.emit('.')
+			return l.handleNumericStandardBase()
+		case nc == 'x':
+			return l.handleNumericNonStandardBase(16)
+		case nc == 'o':
+			l.inputPos += 2
+			return l.handleNumericNonStandardBase(8)
+		case isDigit(nc):
+			return l.handleNumericNonStandardBase(8)
+		case nc == 'b':
+			l.inputPos += 2
+			return l.handleNumericNonStandardBase(2)
+		}
+		l.emit('0')
+		l.skipBigIntSuffix()
+		return jsonState
+	case l.isInfinity():
+		// -Infinity
+		l.emitString("Infinity")
+		return jsonState
+	}
+	return errorState
+}
+
+func (l *lexer) handleNumericStandardBase() state {
+	c := l.nextChar()
+	for {
+		if c != '_' {
+			l.emit(c)
+		} else {
+			l.inputPos++
+		}
+		c = toLower(l.char(l.inputPos))
+		if !(isDigit(c) || c == '.' || c == 'e' || c == 'E' || c == '+' || c == '-' || c == '_') {
+			break
+		}
+	}
+	if l.lastChar() == '.' {
+		l.emitInPlace('0')
+	}
+	l.skipBigIntSuffix()
+	return jsonState
+}
+
+// handleNumericNonStandardBase mirrors handle_numeric_non_standard_base, math/big does the conversion.
+func (l *lexer) handleNumericNonStandardBase(base int) state {
+	if base == 16 && digitValue(l.char(l.inputPos+2)) < 16 {
+		l.inputPos += 2
+	}
+	var digits []byte
+	for {
+		c := l.char(l.inputPos)
+		if digitValue(c) < base {
+			digits = append(digits, c)
+		} else if !(c == '_' && len(digits) > 0 && digitValue(l.char(l.inputPos+1)) < base) {
+			break
+		}
+		l.inputPos++
+	}
+	n := new(big.Int)
+	if len(digits) > 0 {
+		n.SetString(string(digits), base)
+	}
+	l.out = n.Append(l.out, 10)
+	l.skipBigIntSuffix()
+	return jsonState
+}
+
+// skipBigIntSuffix mirrors skip_bigint_suffix.
+func (l *lexer) skipBigIntSuffix() {
+	if l.char(l.inputPos) == 'n' {
+		l.inputPos++
+	}
+}
+
+func (l *lexer) handleUnrecognized() state {
+	l.emitInPlace('"')
+	var currentlyQuotedWith byte
+
+	l.unrecognizedNestingDepth = 0
+	for {
+		c := l.char(l.inputPos)
+
+		switch c {
+		case '\\':
+			l.emitInPlace('\\')
+			l.emit('\\')
+
+		case '\'', '"', '`':
+			if c == '"' {
+				l.emitInPlace('\\')
+				l.emit('"')
+			} else {
+				l.emit(c)
+			}
+
+			if currentlyQuotedWith == 0 {
+				currentlyQuotedWith = c
+			} else if currentlyQuotedWith == c {
+				currentlyQuotedWith = 0
+			}
+
+		case '{', '[', '<', '(':
+			l.emit(c)
+			l.unrecognizedNestingDepth++
+
+		case '}', ']', '>', ')':
+			if currentlyQuotedWith != 0 && l.unrecognizedNestingDepth > 0 {
+				l.emit(c)
+			} else if l.unrecognizedNestingDepth > 0 {
+				l.emit(c)
+				l.unrecognizedNestingDepth--
+			} else {
+				// remove trailing whitespaces after value
+				for isSpace(l.lastChar()) {
+					l.unemit()
+				}
+				l.emitInPlace('"')
+				return jsonState
+			}
+
+		case ',', ':':
+			if currentlyQuotedWith == 0 && l.unrecognizedNestingDepth <= 0 {
+				// remove trailing whitespaces after key
+				for isSpace(l.lastChar()) {
+					l.unemit()
+				}
+				l.emitInPlace('"')
+				return jsonState
+			}
+			l.emit(c)
+
+		default:
+			l.emit(c)
+		}
+		if l.char(l.inputPos) == 0 {
+			break
+		}
+	}
+
+	return errorState
+}
+
+func (l *lexer) handleComments() {
+	l.inputPos++
+	if l.char(l.inputPos) == '/' {
+		for {
+			l.inputPos++
+			c := l.char(l.inputPos)
+			if c == 0 || c == '\n' {
+				break
+			}
+		}
+	} else if l.char(l.inputPos) == '*' {
+		for {
+			l.inputPos++
+			c := l.char(l.inputPos)
+			nextC := l.char(l.inputPos + 1)
+			if c == 0 || (c == '*' && nextC == '/') {
+				break
+			}
+		}
+		l.inputPos += 2
+	}
+}
+
+// top mirrors top (buffer.c), but returns '\0' for an empty buffer.
+func top(buffer []byte) byte {
+	if len(buffer) == 0 {
+		return 0
+	}
+	return buffer[len(buffer)-1]
+}
+
+// digitValue returns the value of an alphanumeric digit in bases up to 36, or 36 for other characters.
+func digitValue(c byte) int {
+	switch {
+	case isDigit(c):
+		return int(c - '0')
+	case c >= 'a' && c <= 'z':
+		return int(c-'a') + 10
+	case c >= 'A' && c <= 'Z':
+		return int(c-'A') + 10
+	}
+	return 36
+}
@@ -1,3 +1,5 @@
+//go:build cgo && !purego
+
 /*
  * Copyright 2020-2025 Mariusz Obajtek. All rights reserved.
  * License: https://github.com/Nykakin/chompjs/blob/master/LICENSE
@@ -66,10 +68,6 @@ void emit_string_in_place(const char *s, size_t size, struct Lexer* lexer) {
     push_string(&lexer->output, s, size);
 }
 
-void emit_number_in_place(long value, struct Lexer* lexer) {
-    push_number(&lexer->output, value);
-}
-
 void init_lexer(struct Lexer* lexer, const char* string) {
     lexer->input = string;
     // allocate in advance more memory for output than for input because we might need
@@ -191,6 +189,16 @@ struct State* _handle_string(struct Lexer* lexer, const char* string, size_t len
     return &states[JSON_STATE];
 }
 
+// is_infinity tells whether Infinity is at the current position, followed by anything but an identifier character.
+bool is_infinity(struct Lexer* lexer) {
+    const char* position = lexer->input + lexer->input_position;
+    if(strncmp(position, "Infinity", 8) != 0) {
+        return false;
+    }
+    char next_char = position[8];
+    return next_char != '_' && !isalnum((unsigned char)next_char);
+}
+
 struct State* value(struct Lexer* lexer) {
     char c = next_char(lexer);
     const char* position = lexer->input + lexer->input_position;
@@ -213,6 +221,9 @@ struct State* value(struct Lexer* lexer) {
         return &states[JSON_STATE];
     } else if(strncmp(position, "NaN", 3) == 0) {
         return _handle_string(lexer, "NaN", 3);
+    } else if(!lexer->is_key && is_infinity(lexer)) {
+        emit_string("Infinity", 8, lexer);
+        return &states[JSON_STATE];
     } else {
         return handle_unrecognized(lexer);
     }
@@ -300,8 +311,13 @@ struct State* handle_numeric(struct Lexer* lexer) {
             return handle_numeric_non_standard_base(lexer, 2);
         } else {
             emit('0', lexer);
+            skip_bigint_suffix(lexer);
             return &states[JSON_STATE];
         }
+    } else if(is_infinity(lexer)) {
+        // -Infinity
+        emit_string("Infinity", 8, lexer);
+        return &states[JSON_STATE];
     } else {
         return &states[ERROR_STATE];
     }
@@ -321,14 +337,75 @@ struct State* handle_numeric_standard_base(struct Lexer* lexer) {
     if(last_char(lexer) == '.') {
         emit_in_place('0', lexer);
     }
+    skip_bigint_suffix(lexer);
     return &states[JSON_STATE];
 }
 
+// digit_value returns the value of an alphanumeric digit in bases up to 36, or 36 for other characters.
+int digit_value(char c) {
+    if(isdigit((unsigned char)c)) {
+        return c - '0';
+    } else if(c >= 'a' && c <= 'z') {
+        return c - 'a' + 10;
+    } else if(c >= 'A' && c <= 'Z') {
+        return c - 'A' + 10;
+    }
+    return 36;
+}
+
+// skip_bigint_suffix skips the n ending BigInt literals such as 123n.
+void skip_bigint_suffix(struct Lexer* lexer) {
+    if(lexer->input[lexer->input_position] == 'n') {
+        lexer->input_position += 1;
+    }
+}
+
+// handle_numeric_non_standard_base converts a hexadecimal, octal or binary literal of any size into a decimal one.
+// Numeric separators may come between the digits.
 struct State* handle_numeric_non_standard_base(struct Lexer* lexer, int base) {
-    char* end;
-    long n = strtol(lexer->input + lexer->input_position, &end, base);
-    emit_number_in_place(n, lexer);
-    lexer->input_position = end - lexer->input;
+    const char* input = lexer->input;
+    if(base == 16 && digit_value(input[lexer->input_position+2]) < 16) {
+        lexer->input_position += 2;
+    }
+    size_t start = lexer->input_position;
+    size_t digits = 0;
+    for(;;) {
+        char c = input[lexer->input_position];
+        if(digit_value(c) < base) {
+            digits += 1;
+        } else if(!(c == '_' && digits > 0 && digit_value(input[lexer->input_position+1]) < base)) {
+            break;
+        }
+        lexer->input_position += 1;
+    }
+
+    // decimal digits of the value, the least significant first, there are fewer than twice as many as the digits in base 16
+    unsigned char* decimal = calloc(2*digits + 1, 1);
+    size_t length = 1;
+    for(size_t i = start; i < lexer->input_position; i++) {
+        if(input[i] == '_') {
+            continue;
+        }
+        int carry = digit_value(input[i]);
+        for(size_t j = 0; j < length; j++) {
+            int value = decimal[j]*base + carry;
+            decimal[j] = value % 10;
+            carry = value / 10;
+        }
+        while(carry > 0) {
+            decimal[length++] = carry % 10;
+            carry /= 10;
+        }
+    }
+    while(length > 1 && decimal[length-1] == 0) {
+        length -= 1;
+    }
+    for(size_t j = length; j > 0; j--) {
+        emit_in_place('0' + decimal[j-1], lexer);
+    }
+    free(decimal);
+
+    skip_bigint_suffix(lexer);
     return &states[JSON_STATE];
 }
 
@@ -32,7 +32,7 @@ struct State* error(struct Lexer* lexer);
     * handle_quoted - handles quoted strings
     * handle_numeric - handle numbers
     * handle_numeric_standard_base - handle numbers in standard base-10
-    * handle_numeric_non_standard_base - handle numbers in non-standard bases (hex, oct)
+    * handle_numeric_non_standard_base - handle numbers in non-standard bases (hex, oct, bin) of any size
     * handle_unrecognized - save all unrecognized data as a string
 */
 struct State* handle_quoted(struct Lexer* lexer);
@@ -92,12 +92,12 @@ void emit_string(const char *s, size_t size, struct Lexer* lexer);
 /** Send string to output buffer, keep old input position */
 void emit_string_in_place(const char *s, size_t size, struct Lexer* lexer);
 
-/** Send number to output buffer, keep old input position */
-void emit_number_in_place(long value, struct Lexer* lexer);
-
 /** Handle comments in JSON body */
 void handle_comments(struct Lexer* lexer);
 
+/** Skip the n suffix of a BigInt literal */
+void skip_bigint_suffix(struct Lexer* lexer);
+
 /** Initialize main lexer object */
 void init_lexer(struct Lexer* lexer, const char* string);
 
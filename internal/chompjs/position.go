@@ -0,0 +1,108 @@
+package chompjs
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// snippetRadius is the amount of bytes of the input Error.Snippet holds on both sides of the offset.
+const snippetRadius = 40
+
+// origin is the position of the first character of a part of the input in the whole input,
+// the zero value is the beginning of the input.
+type origin struct {
+	offset int
+	// lines is the amount of lines before the part
+	lines int
+	// column is the amount of characters before the part in its first line
+	column int
+}
+
+// locate returns the 1-based line and column, counted in characters, of offset in input, which starts at o.
+func (o origin) locate(input string, offset int) (int, int) {
+	if offset > len(input) {
+		offset = len(input)
+	}
+	before := input[:offset]
+	lineStart := strings.LastIndexByte(before, '\n') + 1
+	return o.shift(strings.Count(before, "\n")+1, countChars(before[lineStart:])+1)
+}
+
+// shift converts the line and column of a part of the input, which starts at o, into the ones of the whole input.
+func (o origin) shift(line, column int) (int, int) {
+	if line == 1 {
+		column += o.column
+	}
+	return line + o.lines, column
+}
+
+// advance moves o past consumed.
+func (o *origin) advance(consumed string) {
+	o.offset += len(consumed)
+	if lineStart := strings.LastIndexByte(consumed, '\n') + 1; lineStart > 0 {
+		o.lines += strings.Count(consumed, "\n")
+		o.column = countChars(consumed[lineStart:])
+	} else {
+		o.column += countChars(consumed)
+	}
+}
+
+// cursor locates increasing offsets of input, counting each character once.
+type cursor struct {
+	input  string
+	offset int
+	// line and column are 1-based position of offset
+	line, column int
+}
+
+func newCursor(input string) *cursor {
+	return &cursor{input: input, line: 1, column: 1}
+}
+
+// moveTo returns the 1-based line and column, counted in characters, of offset.
+func (c *cursor) moveTo(offset int) (int, int) {
+	if offset < c.offset {
+		*c = cursor{input: c.input, line: 1, column: 1}
+	}
+	if offset > len(c.input) {
+		offset = len(c.input)
+	}
+	passed := c.input[c.offset:offset]
+	if lineStart := strings.LastIndexByte(passed, '\n') + 1; lineStart > 0 {
+		c.line += strings.Count(passed, "\n")
+		c.column = countChars(passed[lineStart:]) + 1
+	} else {
+		c.column += countChars(passed)
+	}
+	c.offset = offset
+	return c.line, c.column
+}
+
+// countChars counts the characters of s, a character split between two parts of the input is counted in the first one.
+func countChars(s string) int {
+	n := 0
+	for i := 0; i < len(s); i++ {
+		if utf8.RuneStart(s[i]) {
+			n++
+		}
+	}
+	return n
+}
+
+// snippet returns input around offset, cut at character boundaries.
+func snippet(input string, offset int) string {
+	start, end := offset-snippetRadius, offset+snippetRadius
+	if start < 0 {
+		start = 0
+	}
+	if end > len(input) {
+		end = len(input)
+	}
+	for start > 0 && !utf8.RuneStart(input[start]) {
+		start--
+	}
+	for end < len(input) && !utf8.RuneStart(input[end]) {
+		end++
+	}
+	return input[start:end]
+}
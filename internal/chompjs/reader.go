@@ -0,0 +1,87 @@
+package chompjs
+
+import (
+	"context"
+	"io"
+	"unicode/utf8"
+)
+
+// readChunkSize is the minimum amount of bytes FixReader reads from its reader at once.
+const readChunkSize = 64 * 1024
+
+// FixReader is FixStrings reading its input from r in chunks.
+// Every object is sent as soon as it's closed, and only the object being parsed is kept in memory,
+// so the memory used is bounded by the size of the largest object rather than the size of the input.
+// Positions of the objects and errors refer to the whole input read, and both are the ones FixStrings finds in it,
+// wherever the chunks end: the lexer state an object starts in is carried into the next chunk.
+// An error returned by r, other than io.EOF, is sent into the error channel.
+func FixReader(r io.Reader) (<-chan *Object, <-chan error) {
+	return FixReaderContext(context.Background(), r, Options{})
+}
+
+// FixReaderContext is FixReader bound to ctx, see FixStringsContext.
+func FixReaderContext(ctx context.Context, r io.Reader, opts Options) (<-chan *Object, <-chan error) {
+	dataChannel := make(chan *Object)
+	errChannel := make(chan error, 1)
+	f := &fixer{ctx: ctx, opts: opts, dataChannel: dataChannel, errChannel: errChannel, reported: -1}
+
+	go func() {
+		defer close(dataChannel)
+		defer close(errChannel)
+
+		var buffer []byte
+		toRead := readChunkSize
+		for {
+			var readErr error
+			buffer, readErr = readAtLeast(r, buffer, toRead)
+			eof := readErr == io.EOF
+			// the objects finished before a read error are still sent
+			consumed, ok := f.fix(string(buffer), eof)
+			if !ok {
+				reportDone(ctx, errChannel)
+				return
+			}
+			if readErr != nil && !eof {
+				sendError(ctx, errChannel, readErr)
+				return
+			}
+			if eof {
+				return
+			}
+			// keep the unfinished object only
+			f.origin.advance(string(buffer[:consumed]))
+			// the snippets of the errors reach back into the input done with
+			if keep := snippetRadius + utf8.UTFMax; consumed >= keep {
+				f.before = string(buffer[consumed-keep : consumed])
+			} else if f.before += string(buffer[:consumed]); len(f.before) > keep {
+				f.before = f.before[len(f.before)-keep:]
+			}
+			buffer = append(buffer[:0], buffer[consumed:]...)
+			// an unfinished object is lexed again from its beginning after each read,
+			// reading at least as much as it's already got keeps the total work linear
+			toRead = readChunkSize
+			if len(buffer) > toRead {
+				toRead = len(buffer)
+			}
+		}
+	}()
+	return dataChannel, errChannel
+}
+
+// readAtLeast appends at least n bytes read from r to buffer, unless r returns an error.
+func readAtLeast(r io.Reader, buffer []byte, n int) ([]byte, error) {
+	if cap(buffer)-len(buffer) < n {
+		grown := make([]byte, len(buffer), len(buffer)+n)
+		copy(grown, buffer)
+		buffer = grown
+	}
+	for read := 0; read < n; {
+		m, err := r.Read(buffer[len(buffer):cap(buffer)])
+		buffer = buffer[:len(buffer)+m]
+		read += m
+		if err != nil {
+			return buffer, err
+		}
+	}
+	return buffer, nil
+}
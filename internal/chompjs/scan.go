@@ -0,0 +1,125 @@
+package chompjs
+
+// skipObject returns the position right after the bracket closing the depth brackets open at position of input,
+// or len(input) if they aren't closed. Strings and comments are skipped, so brackets inside them are not counted.
+func skipObject(input string, position, depth int) int {
+	i := position
+	for i < len(input) && depth > 0 {
+		switch c := input[i]; c {
+		case '{', '[':
+			depth++
+		case '}', ']':
+			depth--
+		case '"', '\'', '`':
+			i = skipString(input, i)
+			continue
+		case '/':
+			if j := skipComment(input, i); j > i {
+				i = j
+				continue
+			}
+		}
+		i++
+	}
+	if i > len(input) {
+		i = len(input)
+	}
+	return i
+}
+
+// skipString returns the position right after the string starting at position of input,
+// or len(input) if it isn't terminated.
+func skipString(input string, position int) int {
+	quote := input[position]
+	for i := position + 1; i < len(input); i++ {
+		switch input[i] {
+		case '\\':
+			i++
+		case quote:
+			return i + 1
+		}
+	}
+	return len(input)
+}
+
+// skipComment returns the position right after the comment starting at position of input,
+// or position if there's no comment there.
+func skipComment(input string, position int) int {
+	if position+1 >= len(input) {
+		return position
+	}
+	switch input[position+1] {
+	case '/':
+		for i := position + 2; i < len(input); i++ {
+			if input[i] == '\n' {
+				return i + 1
+			}
+		}
+		return len(input)
+	case '*':
+		for i := position + 2; i+1 < len(input); i++ {
+			if input[i] == '*' && input[i+1] == '/' {
+				return i + 2
+			}
+		}
+		return len(input)
+	}
+	return position
+}
+
+// skipBlank returns the position of the first character from position i of input on, which is neither a space nor a part of a comment.
+func skipBlank(input string, i int) int {
+	for {
+		i = skipSpaces(input, i)
+		if i >= len(input) || input[i] != '/' {
+			return i
+		}
+		next := skipComment(input, i)
+		if next == i {
+			return i
+		}
+		i = next
+	}
+}
+
+// MatchingBracket returns the position of the bracket, either a parenthesis, a square or a curly one,
+// closing the one at position open of input. Strings, comments and nested brackets are skipped.
+// It returns false if the bracket isn't closed.
+func MatchingBracket(input string, open int) (int, bool) {
+	var nesting []byte
+	for i := open; i < len(input); {
+		switch c := input[i]; c {
+		case '(', '[', '{':
+			nesting = append(nesting, c)
+		case ')', ']', '}':
+			if len(nesting) == 0 || nesting[len(nesting)-1] != opening(c) {
+				return 0, false
+			}
+			nesting = nesting[:len(nesting)-1]
+			if len(nesting) == 0 {
+				return i, true
+			}
+		case '"', '\'', '`':
+			i = skipString(input, i)
+			continue
+		case '/':
+			if j := skipComment(input, i); j > i {
+				i = j
+				continue
+			}
+		}
+		i++
+	}
+	return 0, false
+}
+
+// opening returns the bracket closed by c.
+func opening(c byte) byte {
+	switch c {
+	case ')':
+		return '('
+	case ']':
+		return '['
+	}
+	return '{'
+}
@@ -0,0 +1,54 @@
+// Package memo memoizes the resolution of entries which refer to each other by id, such as the entries
+// of an Apollo cache or the rows of a flight payload, while references may run into cycles.
+package memo
+
+// Memo holds the entries being resolved and the ones resolved. Resolved entries are stored under a key K,
+// which may tell apart the ways an entry is resolved, such as the depth it's resolved at.
+//
+// An entry referring, directly or through other entries, to an entry it's nested in depends on where it's referred from:
+// the reference is kept rather than resolved. Such an entry isn't stored then, and neither are the entries it's nested in,
+// up to the one the cycle runs into.
+type Memo[K comparable, V any] struct {
+	// resolving holds the ids of the entries being resolved along with their nesting level.
+	resolving map[string]int
+	resolved  map[K]V
+	// cycle is the lowest nesting level of the entries the entry being resolved runs into a cycle with.
+	cycle int
+}
+
+// noCycle is the cycle of an entry which doesn't run into a cycle.
+const noCycle = int(^uint(0) >> 1)
+
+// New returns a Memo resolving the entry root.
+func New[K comparable, V any](root string) *Memo[K, V] {
+	return &Memo[K, V]{resolving: map[string]int{root: 0}, resolved: map[K]V{}, cycle: noCycle}
+}
+
+// Resolving tells whether the entry id is being resolved, i.e. a reference to it runs into a cycle.
+func (m *Memo[K, V]) Resolving(id string) bool {
+	level, ok := m.resolving[id]
+	if ok && level < m.cycle {
+		m.cycle = level
+	}
+	return ok
+}
+
+// Resolve returns the entry id stored under key, or resolves it with resolve, which may resolve other entries.
+// The entry mustn't be being resolved, which Resolving tells.
+func (m *Memo[K, V]) Resolve(id string, key K, resolve func() V) V {
+	if resolved, ok := m.resolved[key]; ok {
+		return resolved
+	}
+	level, cycle := len(m.resolving), m.cycle
+	m.resolving[id] = level
+	m.cycle = noCycle
+	resolved := resolve()
+	delete(m.resolving, id)
+	if m.cycle >= level {
+		m.resolved[key] = resolved
+		m.cycle = cycle
+	} else if cycle < m.cycle {
+		m.cycle = cycle
+	}
+	return resolved
+}
@@ -0,0 +1,143 @@
+package gompjs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/proway2/gompjs/internal/memo"
+)
+
+// ErrUnknownRoot is returned by DenormalizeApollo if the root entry isn't an object of the cache.
+var ErrUnknownRoot = errors.New("unknown root entry")
+
+// ApolloRef replaces a reference DenormalizeApollo doesn't resolve. It's marshaled as an Apollo Client 3 reference.
+type ApolloRef struct {
+	// ID is the id of the entry referred to.
+	ID     string
+	Reason ApolloRefReason
+}
+
+// ApolloRefReason tells why a reference isn't resolved.
+type ApolloRefReason int
+
+const (
+	// ApolloMissing is a reference to an entry which isn't in the cache.
+	ApolloMissing ApolloRefReason = iota
+	// ApolloCycle is a reference to an entry which is being resolved, i.e. the entry holding it or one of its parents.
+	ApolloCycle
+	// ApolloMaxDepth is a reference nested deeper than the maximum depth.
+	ApolloMaxDepth
+)
+
+func (r ApolloRef) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]string{"__ref": r.ID})
+}
+
+// DenormalizeApollo returns the entry root, "ROOT_QUERY" if it's empty, of the normalized Apollo cache state,
+// such as the object assigned to window.__APOLLO_STATE__, with the references to other entries replaced by the entries.
+//
+// Both Apollo Client 3 references, `{"__ref": "Product:1"}`, and Apollo Client 2 ones, `{"type": "id", "id": "Product:1"}`,
+// are resolved, and Apollo Client 2 JSON scalars, `{"type": "json", "json": ...}`, are unwrapped.
+// References to missing entries are replaced by ApolloRef, as well as the ones to an entry which is being resolved,
+// so cyclic caches are resolved too. If maxDepth is positive, references nested deeper than maxDepth entries are replaced too.
+// The references to an entry share the map it's resolved into, or one map per depth with a maximum depth,
+// but for the entries of a cycle, whose maps tell where the cycle was cut.
+// The state isn't modified.
+func DenormalizeApollo(state map[string]any, root string, maxDepth int) (map[string]any, error) {
+	if root == "" {
+		root = "ROOT_QUERY"
+	}
+	entry, ok := state[root].(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownRoot, root)
+	}
+	d := denormalizer{state: state, maxDepth: maxDepth, entries: memo.New[resolvedEntry, map[string]any](root)}
+	return d.object(entry, 0), nil
+}
+
+// denormalizer resolves the references of an Apollo cache.
+type denormalizer struct {
+	state    map[string]any
+	maxDepth int
+	// entries holds the entries being resolved and the ones resolved, which share their maps.
+	entries *memo.Memo[resolvedEntry, map[string]any]
+}
+
+// resolvedEntry is an entry resolved at a depth, which only matters with a maximum depth.
+type resolvedEntry struct {
+	id    string
+	depth int
+}
+
+func (d *denormalizer) value(value any, depth int) any {
+	switch v := value.(type) {
+	case map[string]any:
+		if id, ok := apolloReference(v); ok {
+			return d.reference(id, depth)
+		}
+		if scalar, ok := v["json"]; ok && v["type"] == "json" && len(v) == 2 {
+			return scalar
+		}
+		return d.object(v, depth)
+	case []any:
+		resolved := make([]any, len(v))
+		for i, element := range v {
+			resolved[i] = d.value(element, depth)
+		}
+		return resolved
+	}
+	return value
+}
+
+func (d *denormalizer) object(object map[string]any, depth int) map[string]any {
+	resolved := make(map[string]any, len(object))
+	for key, value := range object {
+		resolved[key] = d.value(value, depth)
+	}
+	return resolved
+}
+
+// reference returns the entry id resolved, or ApolloRef if it can't be resolved.
+func (d *denormalizer) reference(id string, depth int) any {
+	entry, ok := d.state[id].(map[string]any)
+	if !ok {
+		return ApolloRef{ID: id, Reason: ApolloMissing}
+	}
+	if d.entries.Resolving(id) {
+		return ApolloRef{ID: id, Reason: ApolloCycle}
+	}
+	if d.maxDepth > 0 && depth >= d.maxDepth {
+		return ApolloRef{ID: id, Reason: ApolloMaxDepth}
+	}
+	// with a maximum depth, an entry nested deeper has fewer levels left before its references are cut
+	key := resolvedEntry{id: id}
+	if d.maxDepth > 0 {
+		key.depth = depth
+	}
+	return d.entries.Resolve(id, key, func() map[string]any {
+		return d.object(entry, depth+1)
+	})
+}
+
+// apolloReference returns the id of the entry object refers to, if it's a reference.
+func apolloReference(object map[string]any) (string, bool) {
+	if ref, ok := object["__ref"].(string); ok && len(object) == 1 {
+		return ref, true
+	}
+	if object["type"] != "id" {
+		return "", false
+	}
+	id, ok := object["id"].(string)
+	if !ok {
+		return "", false
+	}
+	for key := range object {
+		switch key {
+		case "type", "id", "generated", "typename":
+		default:
+			return "", false
+		}
+	}
+	return id, true
+}
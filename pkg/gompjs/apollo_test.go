@@ -0,0 +1,134 @@
+package gompjs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestDenormalizeApollo(t *testing.T) {
+	input := `window.__APOLLO_STATE__ = {
+		"ROOT_QUERY": {"__typename": "Query", "product({\"id\":1})": {"__ref": "Product:1"}, "missing": {"__ref": "Product:9"}},
+		"Product:1": {"__typename": "Product", "name": "Lamp", "brand": {"__ref": "Brand:1"}, "related": [{"__ref": "Product:2"}]},
+		"Product:2": {"__typename": "Product", "name": "Shade", "brand": {"__ref": "Brand:1"}, "related": [{"__ref": "Product:1"}]},
+		"Brand:1": {"__typename": "Brand", "name": "Acme", "meta": {"type": "json", "json": {"founded": 1900}}}
+	};`
+	value, err := ExtractAssignment(&input, "window.__APOLLO_STATE__")
+	if err != nil {
+		t.Fatalf("ExtractAssignment() error = %v", err)
+	}
+	state := value.(map[string]any)
+	brand := map[string]any{"__typename": "Brand", "name": "Acme", "meta": map[string]any{"founded": 1900.0}}
+	tests := []struct {
+		name     string
+		maxDepth int
+		want     map[string]any
+	}{
+		{
+			name: "unlimited depth",
+			want: map[string]any{
+				"__typename": "Query",
+				"product({\"id\":1})": map[string]any{
+					"__typename": "Product", "name": "Lamp", "brand": brand,
+					"related": []any{map[string]any{
+						"__typename": "Product", "name": "Shade", "brand": brand,
+						"related": []any{ApolloRef{ID: "Product:1", Reason: ApolloCycle}},
+					}},
+				},
+				"missing": ApolloRef{ID: "Product:9", Reason: ApolloMissing},
+			},
+		},
+		{
+			name:     "max depth",
+			maxDepth: 1,
+			want: map[string]any{
+				"__typename": "Query",
+				"product({\"id\":1})": map[string]any{
+					"__typename": "Product", "name": "Lamp",
+					"brand":   ApolloRef{ID: "Brand:1", Reason: ApolloMaxDepth},
+					"related": []any{ApolloRef{ID: "Product:2", Reason: ApolloMaxDepth}},
+				},
+				"missing": ApolloRef{ID: "Product:9", Reason: ApolloMissing},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DenormalizeApollo(state, "", tt.maxDepth)
+			if err != nil {
+				t.Fatalf("DenormalizeApollo() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DenormalizeApollo() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	// the state isn't modified
+	if ref := state["Product:1"].(map[string]any)["brand"]; !reflect.DeepEqual(ref, map[string]any{"__ref": "Brand:1"}) {
+		t.Errorf("DenormalizeApollo() modified the state: %v", ref)
+	}
+}
+
+func TestDenormalizeApolloClient2(t *testing.T) {
+	state := map[string]any{
+		"ROOT_QUERY": map[string]any{
+			"viewer": map[string]any{"type": "id", "id": "User:1", "generated": false, "typename": "User"},
+		},
+		"User:1": map[string]any{
+			"name":    "Ann",
+			"friends": []any{map[string]any{"type": "id", "id": "$User:1.friends.0", "generated": true}},
+		},
+		"$User:1.friends.0": map[string]any{"name": "Bob", "type": "id"},
+	}
+	got, err := DenormalizeApollo(state, "ROOT_QUERY", 0)
+	if err != nil {
+		t.Fatalf("DenormalizeApollo() error = %v", err)
+	}
+	want := map[string]any{
+		"viewer": map[string]any{
+			"name":    "Ann",
+			"friends": []any{map[string]any{"name": "Bob", "type": "id"}},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DenormalizeApollo() = %v, want %v", got, want)
+	}
+}
+
+func TestDenormalizeApolloShared(t *testing.T) {
+	// every entry refers twice to the next one, so the tree has 2^40 leaves once resolved
+	state := map[string]any{"ROOT_QUERY": map[string]any{"first": map[string]any{"__ref": "Node:0"}}}
+	for i := 0; i < 40; i++ {
+		next := map[string]any{"__ref": fmt.Sprintf("Node:%d", i+1)}
+		state[fmt.Sprintf("Node:%d", i)] = map[string]any{"left": next, "right": next}
+	}
+	state["Node:40"] = map[string]any{"leaf": true, "back": map[string]any{"__ref": "Node:39"}}
+	got, err := DenormalizeApollo(state, "", 0)
+	if err != nil {
+		t.Fatalf("DenormalizeApollo() error = %v", err)
+	}
+	node := got["first"].(map[string]any)
+	for i := 0; i < 40; i++ {
+		node = node["right"].(map[string]any)
+	}
+	want := map[string]any{"leaf": true, "back": ApolloRef{ID: "Node:39", Reason: ApolloCycle}}
+	if !reflect.DeepEqual(node, want) {
+		t.Errorf("DenormalizeApollo() leaf = %v, want %v", node, want)
+	}
+	data, err := json.Marshal(node)
+	if err != nil || string(data) != `{"back":{"__ref":"Node:39"},"leaf":true}` {
+		t.Errorf("json.Marshal() = %s, %v", data, err)
+	}
+}
+
+func TestDenormalizeApolloUnknownRoot(t *testing.T) {
+	state := map[string]any{"ROOT_QUERY": "not an object"}
+	for _, root := range []string{"", "ROOT_MUTATION"} {
+		if _, err := DenormalizeApollo(state, root, 0); !errors.Is(err, ErrUnknownRoot) {
+			t.Errorf("DenormalizeApollo(%q) error = %v, want %v", root, err, ErrUnknownRoot)
+		}
+	}
+}
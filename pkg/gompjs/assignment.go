@@ -0,0 +1,47 @@
+package gompjs
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/proway2/gompjs/internal/chompjs"
+)
+
+var (
+	// ErrInvalidTarget is returned by ExtractAssignment and ExtractCalls for a target which is not a member expression.
+	ErrInvalidTarget = errors.New("invalid assignment target")
+	// ErrNoAssignment is returned by ExtractAssignment if there's no object assigned to the target.
+	ErrNoAssignment = errors.New("no object is assigned to the target")
+)
+
+// ExtractAssignment parses the object or array literal assigned to target, such as "productData",
+// "window.__INITIAL_STATE__" or `App.config["items"]`. Dotted and bracketed properties are equal,
+// so `window["__INITIAL_STATE__"] = {...}` matches the target "window.__INITIAL_STATE__",
+// and declarations with var, let or const match their variable.
+//
+// Assignments of other values are skipped, the first object assigned to target is parsed.
+// Strings and comments of the input are skipped when looking for the assignment.
+func ExtractAssignment(inputStr *string, target string, opts ...Option) (any, error) {
+	o := newOptions(opts)
+	path, ok := chompjs.ParsePath(target)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidTarget, target)
+	}
+	inputStr, err := lexerInput(o, inputStr)
+	if err != nil {
+		return nil, err
+	}
+	start, ok := chompjs.FindAssignment(*inputStr, path)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNoAssignment, target)
+	}
+	var parsedString *string
+	if parsedString, err = chompjs.FixStringAt(inputStr, start); err != nil {
+		return nil, newParseError(err)
+	}
+	data, _, err := o.mergeKeys(*parsedString, o.keyOffsets(*inputStr, start))
+	if err != nil {
+		return nil, err
+	}
+	return o.load(data)
+}
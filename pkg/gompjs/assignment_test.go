@@ -0,0 +1,70 @@
+package gompjs
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestExtractAssignment(t *testing.T) {
+	input := `
+	// window.__INITIAL_STATE__ = {"commented": true};
+	var other = "window.__INITIAL_STATE__ = {'quoted': true}";
+	var productData = {id: 1, tags: ['a', 'b']};
+	let first = [1], second = [2];
+	const items = [];
+	window.__INITIAL_STATE__ = window.__INITIAL_STATE__ || {};
+	if (window.__INITIAL_STATE__ == null) {}
+	foo.window.__INITIAL_STATE__ = {"nested": true};
+	window . __INITIAL_STATE__={user: {name: 'Ann'}};
+	App.config["items"] = [{sku: 'x1'}];
+	window['__DATA__'][0] = {zero: 0};
+	`
+	tests := []struct {
+		target string
+		want   any
+	}{
+		{"productData", map[string]any{"id": 1.0, "tags": []any{"a", "b"}}},
+		{"first", []any{1.0}},
+		{"second", []any{2.0}},
+		{"items", []any{}},
+		{"window.__INITIAL_STATE__", map[string]any{"user": map[string]any{"name": "Ann"}}},
+		{`window["__INITIAL_STATE__"]`, map[string]any{"user": map[string]any{"name": "Ann"}}},
+		{"App.config.items", []any{map[string]any{"sku": "x1"}}},
+		{"window.__DATA__[0]", map[string]any{"zero": 0.0}},
+	}
+	for _, tt := range tests {
+		got, err := ExtractAssignment(&input, tt.target)
+		if err != nil {
+			t.Errorf("ExtractAssignment(%q) error = %v", tt.target, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ExtractAssignment(%q) = %v, want %v", tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestExtractAssignmentErrors(t *testing.T) {
+	input := "var a = 1; b.c = {x: 1}; var d = {e: )};"
+	tests := []struct {
+		target string
+		want   error
+	}{
+		{"a", ErrNoAssignment},
+		{"c", ErrNoAssignment},
+		{"b.c.d", ErrNoAssignment},
+		{"b..c", ErrInvalidTarget},
+		{"", ErrInvalidTarget},
+		{"d", ErrUnexpectedClosingBracket},
+	}
+	for _, tt := range tests {
+		if _, err := ExtractAssignment(&input, tt.target); !errors.Is(err, tt.want) {
+			t.Errorf("ExtractAssignment(%q) error = %v, want %v", tt.target, err, tt.want)
+		}
+	}
+	var parseErr *ParseError
+	if _, err := ExtractAssignment(&input, "d"); !errors.As(err, &parseErr) || parseErr.Offset != 37 {
+		t.Errorf("ExtractAssignment() error = %v, want a *ParseError at 37", err)
+	}
+}
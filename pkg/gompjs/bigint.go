@@ -0,0 +1,55 @@
+package gompjs
+
+// maxSafeInteger is the largest integer float64 holds exactly along with all the integers below it, 2^53-1.
+const maxSafeInteger = "9007199254740991"
+
+// quoteBigInts quotes the integers of the JSON document data which are larger than maxSafeInteger in absolute value,
+// strings are skipped.
+func quoteBigInts(data []byte) []byte {
+	var quoted []byte
+	written := 0
+	inString := false
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case c == '"':
+			inString = !inString
+		case c == '\\' && inString:
+			i++
+		case !inString && (c == '-' || isDigit(c)):
+			end := i
+			integer := true
+			for end < len(data) && (isDigit(data[end]) || data[end] == '-' || data[end] == '+' || data[end] == '.' || data[end] == 'e' || data[end] == 'E') {
+				integer = integer && data[end] != '.' && data[end] != 'e' && data[end] != 'E'
+				end++
+			}
+			if integer && isBigInt(string(data[i:end])) {
+				quoted = append(quoted, data[written:i]...)
+				quoted = append(quoted, '"')
+				quoted = append(quoted, data[i:end]...)
+				quoted = append(quoted, '"')
+				written = end
+			}
+			i = end - 1
+		}
+	}
+	if quoted == nil {
+		return data
+	}
+	return append(quoted, data[written:]...)
+}
+
+// isBigInt tells whether the JSON integer n is larger than maxSafeInteger in absolute value.
+func isBigInt(n string) bool {
+	if n != "" && n[0] == '-' {
+		n = n[1:]
+	}
+	if len(n) != len(maxSafeInteger) {
+		return len(n) > len(maxSafeInteger)
+	}
+	return n > maxSafeInteger
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,67 @@
+package gompjs
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestBigIntegers(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "hexadecimal past int64",
+			input: "[0xFFFFFFFFFFFFFFFF, -0x8000000000000000, 0x123456789abcdef0123456789abcdef]",
+			want:  "[18446744073709551615,-9223372036854775808,1512366075204170929049582354406559215]",
+		},
+		{
+			name:  "octal and binary",
+			input: "[0o777777777777777777777777, 0b1111111111111111111111111111111111111111111111111111111111111111, 017]",
+			want:  "[4722366482869645213695,18446744073709551615,15]",
+		},
+		{
+			name:  "BigInt suffix",
+			input: "{a: 123n, b: 0n, c: 0x1Fn, d: 0b11n, e: 18446744073709551616n}",
+			want:  `{"a":123,"b":0,"c":31,"d":3,"e":18446744073709551616}`,
+		},
+		{
+			name:  "numeric separators",
+			input: "[1_000_000, 0xFF_FF, 0o7_7, 0b1_0, 0xDEAD_BEEF_DEAD_BEEF_0001n]",
+			want:  "[1000000,65535,63,2,1051570404382037444067329]",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseAs[json.RawMessage](&tt.input)
+			if err != nil {
+				t.Fatalf("ParseAs() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("ParseAs() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithBigIntStrings(t *testing.T) {
+	input := `{id: 0xFFFFFFFFFFFFFFFF, safe: 9007199254740991, unsafe: -9007199254740992, float: 1.5e300, text: "12345678901234567890", list: [123456789012345678901n]}`
+	got, err := Parse(&input, WithBigIntStrings())
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	want := map[string]any{
+		"id":     "18446744073709551615",
+		"safe":   9007199254740991.0,
+		"unsafe": "-9007199254740992",
+		"float":  1.5e300,
+		"text":   "12345678901234567890",
+		"list":   []any{"123456789012345678901"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse() = %v, want %v", got, want)
+	}
+}
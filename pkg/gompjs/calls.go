@@ -0,0 +1,94 @@
+package gompjs
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/proway2/gompjs/internal/chompjs"
+)
+
+// Expr is the source text of an argument which is not a literal, such as `window.location.href` or `function() {...}`.
+type Expr string
+
+// Call is a call of a function found by ExtractCalls.
+type Call struct {
+	// Callee is the function called, properties in brackets are written as dotted ones.
+	Callee string
+	// Args are the arguments of the call. Literals are loaded following the options of ExtractCalls,
+	// any other argument is its source text as Expr.
+	Args []any
+	// Offset is the byte offset of the callee in the input.
+	Offset int
+}
+
+// ExtractCalls returns every call of the functions names, such as "dataLayer.push" or "ga", in the order they are found.
+// Names are member expressions, just as the targets of ExtractAssignment are. Strings and comments of the input are skipped,
+// and calls nested in the arguments of other calls are found too.
+//
+// Objects, arrays, strings, numbers, booleans and null passed as arguments are parsed with the lexer,
+// so JavaScript objects such as `{event: 'view'}` are literals too. A literal which can't be loaded,
+// such as `{a: NaN}` with the default loader, is returned as Expr.
+//
+// The options apply to the input and to the literals just as they do for ExtractAssignment.
+func ExtractCalls(inputStr *string, names []string, opts ...Option) ([]Call, error) {
+	o := newOptions(opts)
+	callees := make([][]string, 0, len(names))
+	for _, name := range names {
+		path, ok := chompjs.ParsePath(name)
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidTarget, name)
+		}
+		callees = append(callees, path)
+	}
+	inputStr, err := lexerInput(o, inputStr)
+	if err != nil {
+		return nil, err
+	}
+	var calls []Call
+	for _, found := range chompjs.FindCalls(*inputStr, callees) {
+		call := Call{Callee: strings.Join(found.Callee, "."), Offset: found.Start, Args: make([]any, 0, len(found.Args))}
+		for _, arg := range found.Args {
+			if arg.Literal {
+				value, ok, err := o.parseLiteral(inputStr, arg)
+				if err != nil {
+					return nil, err
+				}
+				if ok {
+					call.Args = append(call.Args, value)
+					continue
+				}
+			}
+			call.Args = append(call.Args, Expr((*inputStr)[arg.Start:arg.End]))
+		}
+		calls = append(calls, call)
+	}
+	return calls, nil
+}
+
+// parseLiteral parses the literal argument arg of input, ok is false if it can't be loaded.
+// The error is the one of the DuplicateError policy.
+func (o Options) parseLiteral(inputStr *string, arg chompjs.Arg) (value any, ok bool, err error) {
+	parsedString, err := chompjs.FixArgs(inputStr, arg.Before, arg.After)
+	if err != nil {
+		return nil, false, nil
+	}
+	data, _, err := o.mergeKeys(*parsedString, o.argKeyOffsets(*inputStr, arg))
+	if err != nil {
+		return nil, false, err
+	}
+	loaded, err := o.load(data)
+	// the arguments are a JSON array, its only item is the literal
+	if values, isArray := loaded.([]any); err == nil && isArray && len(values) == 1 {
+		return values[0], true, nil
+	}
+	return nil, false, nil
+}
+
+// argKeyOffsets returns the offsets of the keys of the literal argument arg of input, if duplicate keys are looked for.
+func (o Options) argKeyOffsets(input string, arg chompjs.Arg) []int {
+	// a string or a number has no keys, while keyOffsets would look for the next bracket
+	if c := input[arg.Start]; c != '{' && c != '[' {
+		return nil
+	}
+	return o.keyOffsets(input, arg.Start)
+}
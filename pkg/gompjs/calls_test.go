@@ -0,0 +1,101 @@
+package gompjs
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestExtractCalls(t *testing.T) {
+	input := `
+	window.dataLayer = window.dataLayer || [];
+	function ga(command, event) { queue.push(arguments) }
+	dataLayer.push({event: 'view', page: {path: '/p/1'}});
+	// dataLayer.push({event: 'commented'})
+	var s = "ga('send', 'string')";
+	ga('send', 'event', {category: "video", value: -1.5}, true, null);
+	other.dataLayer.push({event: 'other'});
+	initApp({id: 7}, [1, 2], window.location.href, function() { return {a: 1} }, a + 'b',);
+	dataLayer.push({a: NaN}, ga('nested'));
+	`
+	calls, err := ExtractCalls(&input, []string{"dataLayer.push", "ga", "initApp"})
+	if err != nil {
+		t.Fatalf("ExtractCalls() error = %v", err)
+	}
+	want := []Call{
+		{Callee: "dataLayer.push", Args: []any{map[string]any{"event": "view", "page": map[string]any{"path": "/p/1"}}}},
+		{Callee: "ga", Args: []any{"send", "event", map[string]any{"category": "video", "value": -1.5}, true, nil}},
+		{Callee: "initApp", Args: []any{
+			map[string]any{"id": 7.0},
+			[]any{1.0, 2.0},
+			Expr("window.location.href"),
+			Expr("function() { return {a: 1} }"),
+			Expr("a + 'b'"),
+		}},
+		{Callee: "dataLayer.push", Args: []any{Expr("{a: NaN}"), Expr("ga('nested')")}},
+		{Callee: "ga", Args: []any{"nested"}},
+	}
+	if len(calls) != len(want) {
+		t.Fatalf("ExtractCalls() = %+v, want %d calls", calls, len(want))
+	}
+	for i := range want {
+		if calls[i].Callee != want[i].Callee || !reflect.DeepEqual(calls[i].Args, want[i].Args) {
+			t.Errorf("ExtractCalls()[%d] = %#v, want %#v", i, calls[i], want[i])
+		}
+		if got := input[calls[i].Offset : calls[i].Offset+len("ga")]; got != want[i].Callee[:2] {
+			t.Errorf("ExtractCalls()[%d].Offset points at %q", i, got)
+		}
+	}
+}
+
+func TestExtractCallsInvalidName(t *testing.T) {
+	input := "ga()"
+	if _, err := ExtractCalls(&input, []string{"ga", "a..b"}); !errors.Is(err, ErrInvalidTarget) {
+		t.Errorf("ExtractCalls() error = %v, want %v", err, ErrInvalidTarget)
+	}
+}
+
+func TestExtractCallsOptions(t *testing.T) {
+	input := `ga({b: 1, a: NaN}, 0x1fffffffffffff1); ga({a: 1, a: 2})`
+	tests := []struct {
+		name string
+		opts []Option
+		want [][]any
+	}{
+		{"default", nil, [][]any{
+			{Expr("{b: 1, a: NaN}"), float64(0x1fffffffffffff1)},
+			{map[string]any{"a": 2.0}},
+		}},
+		{"non-finite and big integers", []Option{WithNonFinite(NonFiniteNull), WithBigIntStrings()}, [][]any{
+			{map[string]any{"b": 1.0, "a": nil}, "144115188075855857"},
+			{map[string]any{"a": 2.0}},
+		}},
+		{"ordered keys", []Option{WithOrderedKeys(), WithNonFinite(NonFiniteString)}, [][]any{
+			{OrderedMap{{Key: "b", Value: 1.0}, {Key: "a", Value: "NaN"}}, float64(0x1fffffffffffff1)},
+			{OrderedMap{{Key: "a", Value: 2.0}}},
+		}},
+		{"first duplicate", []Option{WithDuplicateKeys(DuplicateFirst)}, [][]any{
+			{Expr("{b: 1, a: NaN}"), float64(0x1fffffffffffff1)},
+			{map[string]any{"a": 1.0}},
+		}},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			calls, err := ExtractCalls(&input, []string{"ga"}, tt.opts...)
+			if err != nil {
+				t.Fatalf("ExtractCalls() error = %v", err)
+			}
+			var got [][]any
+			for _, call := range calls {
+				got = append(got, call.Args)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExtractCalls() args = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+	if _, err := ExtractCalls(&input, []string{"ga"}, WithDuplicateKeys(DuplicateError)); !errors.As(err, new(*DuplicateKeyError)) {
+		t.Errorf("ExtractCalls() error = %v, want *DuplicateKeyError", err)
+	}
+}
@@ -0,0 +1,163 @@
+package gompjs
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/proway2/gompjs/internal/chompjs"
+)
+
+// DataLayer is the data layer of a Google Tag Manager page.
+type DataLayer struct {
+	// Pushes are the objects pushed into the data layer, in the order they are found.
+	Pushes []map[string]any
+	// State is the state of the data layer once every object is pushed.
+	State map[string]any
+}
+
+// ExtractDataLayer collects the objects pushed into the data layer array name, "dataLayer" if it's empty,
+// and merges them into its state just as Google Tag Manager does: nested objects are merged,
+// anything else, arrays included, replaces the previous value, and dotted keys such as "ecommerce.items" are nested ones.
+// The keys of an object are merged in the order of the input, so of {"a.b": 1, a: 5} the later a wins.
+//
+// Both `name.push(...)` and `window.name.push(...)` calls are collected, as well as the objects of an array literal
+// assigned to the data layer, such as `var dataLayer = [{...}]`. Arguments which aren't objects, such as gtag's arguments, are skipped.
+//
+// The options apply to the input and to the objects just as they do for ExtractAssignment, but for WithLoader and WithOrderedKeys:
+// the objects are decoded as Value, whose keys keep their order, and the pushes and the state are maps.
+func ExtractDataLayer(inputStr *string, name string, opts ...Option) (DataLayer, error) {
+	o := newOptions(opts)
+	if name == "" {
+		name = "dataLayer"
+	}
+	path, ok := chompjs.ParsePath(name)
+	if !ok {
+		return DataLayer{}, fmt.Errorf("%w: %q", ErrInvalidTarget, name)
+	}
+	inputStr, err := lexerInput(o, inputStr)
+	if err != nil {
+		return DataLayer{}, err
+	}
+	window := append([]string{"window"}, path...)
+	arrays := [][]string{path, window}
+	type push struct {
+		offset int
+		object *Value
+	}
+	var pushes []push
+	for _, array := range arrays {
+		if start, ok := chompjs.FindAssignment(*inputStr, array); ok && (*inputStr)[start] == '[' {
+			if parsedString, err := chompjs.FixStringAt(inputStr, start); err == nil {
+				value, err := o.decodeObjects(*parsedString, o.keyOffsets(*inputStr, start))
+				if err != nil {
+					return DataLayer{}, err
+				}
+				if value != nil && value.Kind() == KindArray {
+					for i := range value.items {
+						if value.items[i].Kind() == KindObject {
+							pushes = append(pushes, push{offset: start, object: &value.items[i]})
+						}
+					}
+				}
+			}
+		}
+	}
+	pushPath := append(append([]string{}, path...), "push")
+	calls := chompjs.FindCalls(*inputStr, [][]string{pushPath, append(window, "push")})
+	for _, call := range calls {
+		for _, arg := range call.Args {
+			if !arg.Literal || (*inputStr)[arg.Start] != '{' {
+				continue
+			}
+			// the arguments are a JSON array, their only item is the object
+			if parsedString, err := chompjs.FixArgs(inputStr, arg.Before, arg.After); err == nil {
+				value, err := o.decodeObjects(*parsedString, o.keyOffsets(*inputStr, arg.Start))
+				if err != nil {
+					return DataLayer{}, err
+				}
+				if value != nil && len(value.items) == 1 && value.items[0].Kind() == KindObject {
+					pushes = append(pushes, push{offset: arg.Start, object: &value.items[0]})
+				}
+			}
+		}
+	}
+	sort.SliceStable(pushes, func(i, j int) bool {
+		return pushes[i].offset < pushes[j].offset
+	})
+	dataLayer := DataLayer{Pushes: make([]map[string]any, 0, len(pushes)), State: map[string]any{}}
+	for _, p := range pushes {
+		dataLayer.Pushes = append(dataLayer.Pushes, p.object.Any().(map[string]any))
+		// the keys are merged in the order of the input, as a key may be a prefix of a dotted one
+		mergeDataLayer(dataLayer.State, p.object.ordered(o.DuplicateKeys).(OrderedMap))
+	}
+	return dataLayer, nil
+}
+
+// decodeObjects decodes the JSON text data the lexer puts out for the objects of the data layer,
+// keys are the offsets of their keys in the input. It returns nil if data can't be decoded,
+// the error is the one of the DuplicateError policy.
+func (o Options) decodeObjects(data string, keys []int) (*Value, error) {
+	data, _, err := o.mergeKeys(data, keys)
+	if err != nil {
+		return nil, err
+	}
+	value, err := o.decodeValue(data)
+	if err != nil {
+		return nil, nil
+	}
+	return value, nil
+}
+
+// mergeDataLayer merges object into state following the rules of Google Tag Manager, in the order of its keys.
+func mergeDataLayer(state map[string]any, object OrderedMap) {
+	for _, member := range object {
+		// a dotted key is a path of nested objects
+		keys := strings.Split(member.Key, ".")
+		target := state
+		for _, k := range keys[:len(keys)-1] {
+			nested, ok := target[k].(map[string]any)
+			if !ok {
+				nested = map[string]any{}
+				target[k] = nested
+			}
+			target = nested
+		}
+		mergeValue(target, keys[len(keys)-1], member.Value)
+	}
+}
+
+// mergeValue merges value into target[key]: objects are merged, anything else replaces the previous value.
+func mergeValue(target map[string]any, key string, value any) {
+	object, ok := value.(OrderedMap)
+	if !ok {
+		target[key] = unordered(value)
+		return
+	}
+	existing, ok := target[key].(map[string]any)
+	if !ok {
+		existing = map[string]any{}
+		target[key] = existing
+	}
+	for _, member := range object {
+		mergeValue(existing, member.Key, member.Value)
+	}
+}
+
+// unordered returns value with its OrderedMap turned into maps.
+func unordered(value any) any {
+	switch v := value.(type) {
+	case OrderedMap:
+		members := make(map[string]any, len(v))
+		for _, member := range v {
+			members[member.Key] = unordered(member.Value)
+		}
+		return members
+	case []any:
+		for i, item := range v {
+			v[i] = unordered(item)
+		}
+		return v
+	}
+	return value
+}
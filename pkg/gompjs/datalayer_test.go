@@ -0,0 +1,157 @@
+package gompjs
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestExtractDataLayer(t *testing.T) {
+	input := `
+	var dataLayer = [{pageType: 'product', user: {id: 1, tags: ['a', 'b']}}];
+	function gtag() { dataLayer.push(arguments); }
+	gtag('js', new Date());
+	dataLayer.push({event: 'view', user: {loggedIn: true, tags: ['c']}});
+	window.dataLayer.push({'ecommerce.currency': 'EUR', ecommerce: {items: [{id: 'x'}]}}, 'not an object');
+	window.dataLayer.push({ecommerce: {items: [{id: 'y'}]}, pageType: null});
+	other.dataLayer.push({ignored: true});
+	`
+	got, err := ExtractDataLayer(&input, "")
+	if err != nil {
+		t.Fatalf("ExtractDataLayer() error = %v", err)
+	}
+	wantPushes := []map[string]any{
+		{"pageType": "product", "user": map[string]any{"id": 1.0, "tags": []any{"a", "b"}}},
+		{"event": "view", "user": map[string]any{"loggedIn": true, "tags": []any{"c"}}},
+		{"ecommerce.currency": "EUR", "ecommerce": map[string]any{"items": []any{map[string]any{"id": "x"}}}},
+		{"ecommerce": map[string]any{"items": []any{map[string]any{"id": "y"}}}, "pageType": nil},
+	}
+	if !reflect.DeepEqual(got.Pushes, wantPushes) {
+		t.Errorf("ExtractDataLayer().Pushes = %v, want %v", got.Pushes, wantPushes)
+	}
+	wantState := map[string]any{
+		"pageType": nil,
+		"event":    "view",
+		"user":     map[string]any{"id": 1.0, "loggedIn": true, "tags": []any{"c"}},
+		"ecommerce": map[string]any{
+			"currency": "EUR",
+			"items":    []any{map[string]any{"id": "y"}},
+		},
+	}
+	if !reflect.DeepEqual(got.State, wantState) {
+		t.Errorf("ExtractDataLayer().State = %v, want %v", got.State, wantState)
+	}
+	// merging doesn't change the objects pushed
+	if tags := got.Pushes[0]["user"].(map[string]any)["tags"]; !reflect.DeepEqual(tags, []any{"a", "b"}) {
+		t.Errorf("ExtractDataLayer().Pushes[0] is changed: %v", got.Pushes[0])
+	}
+}
+
+func TestExtractDataLayerName(t *testing.T) {
+	input := "gtmLayer.push({a: 1}); dataLayer.push({b: 2})"
+	got, err := ExtractDataLayer(&input, "gtmLayer")
+	if err != nil {
+		t.Fatalf("ExtractDataLayer() error = %v", err)
+	}
+	if want := map[string]any{"a": 1.0}; !reflect.DeepEqual(got.State, want) {
+		t.Errorf("ExtractDataLayer().State = %v, want %v", got.State, want)
+	}
+	if _, err := ExtractDataLayer(&input, "a b"); !errors.Is(err, ErrInvalidTarget) {
+		t.Errorf("ExtractDataLayer() error = %v, want %v", err, ErrInvalidTarget)
+	}
+}
+
+func TestExtractDataLayerOverlappingKeys(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  map[string]any
+	}{
+		{
+			name:  "dotted key first",
+			input: `dataLayer.push({"a.b": 1, a: 5})`,
+			want:  map[string]any{"a": 5.0},
+		},
+		{
+			name:  "dotted key last",
+			input: `dataLayer.push({a: 5, "a.b": 1})`,
+			want:  map[string]any{"a": map[string]any{"b": 1.0}},
+		},
+		{
+			name:  "nested object first",
+			input: `dataLayer.push({a: {b: 1, c: 1}, "a.c": 2})`,
+			want:  map[string]any{"a": map[string]any{"b": 1.0, "c": 2.0}},
+		},
+		{
+			name:  "nested object last",
+			input: `dataLayer.push({"a.c": 2, a: {b: 1, c: 1}})`,
+			want:  map[string]any{"a": map[string]any{"b": 1.0, "c": 1.0}},
+		},
+		{
+			name:  "duplicate key",
+			input: `dataLayer.push({a: {b: 1}, "a.c": 2, a: {d: 3}})`,
+			want:  map[string]any{"a": map[string]any{"c": 2.0, "d": 3.0}},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExtractDataLayer(&tt.input, "")
+			if err != nil {
+				t.Fatalf("ExtractDataLayer() error = %v", err)
+			}
+			if !reflect.DeepEqual(got.State, tt.want) {
+				t.Errorf("ExtractDataLayer().State = %v, want %v", got.State, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractDataLayerOptions(t *testing.T) {
+	input := `var dataLayer = [{inf: Infinity, id: 0x1fffffffffffff1}];
+	dataLayer.push({a: 1, a: 2});
+	dataLayer.push(JSON.parse('{"b": 3}'));`
+	tests := []struct {
+		name       string
+		opts       []Option
+		wantPushes []map[string]any
+		wantState  map[string]any
+	}{
+		{
+			name: "default",
+			wantPushes: []map[string]any{
+				{"inf": "Infinity", "id": float64(0x1fffffffffffff1)},
+				{"a": 2.0},
+			},
+			wantState: map[string]any{"inf": "Infinity", "id": float64(0x1fffffffffffff1), "a": 2.0},
+		},
+		{
+			name: "options",
+			opts: []Option{WithNonFinite(NonFiniteNull), WithBigIntStrings(), WithDuplicateKeys(DuplicateFirst), WithJSONParse()},
+			wantPushes: []map[string]any{
+				{"inf": nil, "id": "144115188075855857"},
+				{"a": 1.0},
+				{"b": 3.0},
+			},
+			wantState: map[string]any{"inf": nil, "id": "144115188075855857", "a": 1.0, "b": 3.0},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExtractDataLayer(&input, "", tt.opts...)
+			if err != nil {
+				t.Fatalf("ExtractDataLayer() error = %v", err)
+			}
+			if !reflect.DeepEqual(got.Pushes, tt.wantPushes) {
+				t.Errorf("ExtractDataLayer().Pushes = %v, want %v", got.Pushes, tt.wantPushes)
+			}
+			if !reflect.DeepEqual(got.State, tt.wantState) {
+				t.Errorf("ExtractDataLayer().State = %v, want %v", got.State, tt.wantState)
+			}
+		})
+	}
+	if _, err := ExtractDataLayer(&input, "", WithDuplicateKeys(DuplicateError)); !errors.As(err, new(*DuplicateKeyError)) {
+		t.Errorf("ExtractDataLayer() error = %v, want *DuplicateKeyError", err)
+	}
+}
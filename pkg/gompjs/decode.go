@@ -0,0 +1,241 @@
+package gompjs
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/proway2/gompjs/internal/chompjs"
+)
+
+// ErrSyntax is returned by DecodeValue for a JSON text it can't decode.
+var ErrSyntax = errors.New("invalid JSON")
+
+// DecodeValue decodes the JSON text data into a Value without reflection.
+// On top of JSON, NaN, Infinity and -Infinity are numbers, and strings may hold JavaScript escape sequences
+// such as \x41 and raw control characters, just as the lexer outputs them.
+func DecodeValue(data []byte) (*Value, error) {
+	return decodeValue(string(data))
+}
+
+func decodeValue(data string) (*Value, error) {
+	d := valueDecoder{data: data}
+	value, err := d.value()
+	if err != nil {
+		return nil, err
+	}
+	if d.skipSpaces(); d.i < len(d.data) {
+		return nil, d.syntaxError("after the value")
+	}
+	return &value, nil
+}
+
+// valueDecoder decodes a JSON text into values, i is the position it's at.
+type valueDecoder struct {
+	data string
+	i    int
+	// items and members stack up the elements of the arrays and objects being decoded,
+	// which are copied once complete, so each array and object takes only the memory its elements need.
+	items   []Value
+	members []Member
+}
+
+func (d *valueDecoder) value() (Value, error) {
+	d.skipSpaces()
+	if d.i >= len(d.data) {
+		return Value{}, fmt.Errorf("%w: unexpected end of input", ErrSyntax)
+	}
+	switch c := d.data[d.i]; {
+	case c == '{':
+		return d.object()
+	case c == '[':
+		return d.array()
+	case c == '"':
+		s, err := d.string()
+		return Value{kind: KindString, text: s}, err
+	case c == '-' || isDigit(c):
+		return d.number()
+	}
+	for _, literal := range [...]struct {
+		text string
+		kind Kind
+	}{
+		{"true", KindBool},
+		{"false", KindBool},
+		{"null", KindNull},
+		{"NaN", KindNumber},
+		{"Infinity", KindNumber},
+	} {
+		if strings.HasPrefix(d.data[d.i:], literal.text) {
+			d.i += len(literal.text)
+			return Value{kind: literal.kind, text: literal.text}, nil
+		}
+	}
+	return Value{}, d.syntaxError("looking for a value")
+}
+
+func (d *valueDecoder) object() (Value, error) {
+	// skips {
+	d.i++
+	if d.skipSpaces(); d.i < len(d.data) && d.data[d.i] == '}' {
+		d.i++
+		return Value{kind: KindObject}, nil
+	}
+	base := len(d.members)
+	for {
+		if d.skipSpaces(); d.i >= len(d.data) || d.data[d.i] != '"' {
+			return Value{}, d.syntaxError("looking for a key")
+		}
+		key, err := d.string()
+		if err != nil {
+			return Value{}, err
+		}
+		if d.skipSpaces(); d.i >= len(d.data) || d.data[d.i] != ':' {
+			return Value{}, d.syntaxError("after a key")
+		}
+		d.i++
+		value, err := d.value()
+		if err != nil {
+			return Value{}, err
+		}
+		d.members = append(d.members, Member{Key: key, Value: value})
+		done, err := d.next('}')
+		if err != nil {
+			return Value{}, err
+		}
+		if done {
+			members := append([]Member(nil), d.members[base:]...)
+			d.members = d.members[:base]
+			return Value{kind: KindObject, members: members}, nil
+		}
+	}
+}
+
+func (d *valueDecoder) array() (Value, error) {
+	// skips [
+	d.i++
+	if d.skipSpaces(); d.i < len(d.data) && d.data[d.i] == ']' {
+		d.i++
+		return Value{kind: KindArray}, nil
+	}
+	base := len(d.items)
+	for {
+		item, err := d.value()
+		if err != nil {
+			return Value{}, err
+		}
+		d.items = append(d.items, item)
+		done, err := d.next(']')
+		if err != nil {
+			return Value{}, err
+		}
+		if done {
+			items := append([]Value(nil), d.items[base:]...)
+			d.items = d.items[:base]
+			return Value{kind: KindArray, items: items}, nil
+		}
+	}
+}
+
+// next skips the comma following an element, it returns true if the closing bracket follows instead.
+func (d *valueDecoder) next(closing byte) (bool, error) {
+	d.skipSpaces()
+	if d.i < len(d.data) {
+		switch d.data[d.i] {
+		case ',':
+			d.i++
+			return false, nil
+		case closing:
+			d.i++
+			return true, nil
+		}
+	}
+	return false, d.syntaxError("after an element")
+}
+
+// string reads the string starting at the current position, escape sequences are decoded as JavaScript does.
+func (d *valueDecoder) string() (string, error) {
+	start := d.i
+	escaped := false
+	for d.i++; d.i < len(d.data); d.i++ {
+		switch d.data[d.i] {
+		case '\\':
+			escaped = true
+			d.i++
+		case '"':
+			d.i++
+			if !escaped {
+				return d.data[start+1 : d.i-1], nil
+			}
+			s, ok := chompjs.UnquoteJS(d.data[start:d.i])
+			if !ok {
+				d.i = start
+				return "", d.syntaxError("in a string")
+			}
+			return s, nil
+		}
+	}
+	return "", fmt.Errorf("%w: unterminated string at offset %d", ErrSyntax, start)
+}
+
+// number reads the number starting at the current position, keeping its text.
+// Apart from -Infinity, it must follow the JSON grammar: -?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?
+func (d *valueDecoder) number() (Value, error) {
+	start := d.i
+	if d.data[d.i] == '-' {
+		d.i++
+		if strings.HasPrefix(d.data[d.i:], "Infinity") {
+			d.i += len("Infinity")
+			return Value{kind: KindNumber, text: d.data[start:d.i]}, nil
+		}
+	}
+	switch {
+	case d.i < len(d.data) && d.data[d.i] == '0':
+		d.i++
+	case d.digits() == 0:
+		return Value{}, d.syntaxError("in a number")
+	}
+	if d.i < len(d.data) && d.data[d.i] == '.' {
+		d.i++
+		if d.digits() == 0 {
+			return Value{}, d.syntaxError("in the fraction of a number")
+		}
+	}
+	if d.i < len(d.data) && (d.data[d.i] == 'e' || d.data[d.i] == 'E') {
+		d.i++
+		if d.i < len(d.data) && (d.data[d.i] == '+' || d.data[d.i] == '-') {
+			d.i++
+		}
+		if d.digits() == 0 {
+			return Value{}, d.syntaxError("in the exponent of a number")
+		}
+	}
+	return Value{kind: KindNumber, text: d.data[start:d.i]}, nil
+}
+
+// digits skips the digits at the current position and returns how many they are.
+func (d *valueDecoder) digits() int {
+	start := d.i
+	for d.i < len(d.data) && isDigit(d.data[d.i]) {
+		d.i++
+	}
+	return d.i - start
+}
+
+func (d *valueDecoder) skipSpaces() {
+	for d.i < len(d.data) {
+		switch d.data[d.i] {
+		case ' ', '\t', '\n', '\r':
+			d.i++
+		default:
+			return
+		}
+	}
+}
+
+func (d *valueDecoder) syntaxError(context string) error {
+	if d.i >= len(d.data) {
+		return fmt.Errorf("%w: unexpected end of input %s", ErrSyntax, context)
+	}
+	return fmt.Errorf("%w: invalid character %q at offset %d %s", ErrSyntax, d.data[d.i], d.i, context)
+}
@@ -0,0 +1,227 @@
+package gompjs
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/proway2/gompjs/internal/chompjs"
+)
+
+// DuplicateKeys tells what becomes of the members of an object sharing a key, e.g. {price: 1, price: 2}.
+type DuplicateKeys int
+
+const (
+	// DuplicateLast keeps the value of the last member at the position of the first one, just as JSON.parse does.
+	// It's the default, duplicate keys are only looked for with WithDuplicateReport, and the loader decides,
+	// encoding/json keeps the last value too.
+	DuplicateLast DuplicateKeys = iota
+	// DuplicateKeepAll keeps every member, so a key may be found many times in an OrderedMap.
+	// The loader decides for other objects.
+	DuplicateKeepAll
+	// DuplicateFirst keeps the value of the first member.
+	DuplicateFirst
+	// DuplicateError fails loading the objects holding duplicate keys with *DuplicateKeyError.
+	// Just as for other loader errors, the streaming functions report it only with WithLoadErrors.
+	DuplicateError
+	// DuplicateCollect collects the values of the members into an array at the position of the first one,
+	// e.g. {"price": [1, 2]}.
+	DuplicateCollect
+)
+
+// DuplicateKey is a member of an object whose key is the key of an earlier member of the object.
+type DuplicateKey struct {
+	Key string
+	// Offset is the byte offset of the key in the input, or -1 if it's unknown.
+	// Just as for ParseError, it refers to the input the lexer gets.
+	Offset int
+}
+
+// DuplicateKeyError reports the duplicate keys of an object loaded with DuplicateError.
+type DuplicateKeyError struct {
+	// Duplicates are the duplicate keys of the object and the objects nested in it, in the order of the input.
+	Duplicates []DuplicateKey
+}
+
+func (e *DuplicateKeyError) Error() string {
+	first := e.Duplicates[0]
+	msg := fmt.Sprintf("duplicate key %q at offset %d", first.Key, first.Offset)
+	if more := len(e.Duplicates) - 1; more > 0 {
+		msg += fmt.Sprintf(" and %d more", more)
+	}
+	return msg
+}
+
+// findsDuplicates tells whether duplicate keys are looked for, either to apply the policy or to report them.
+func (o Options) findsDuplicates() bool {
+	return o.DuplicateKeys != DuplicateLast || o.DuplicateReport != nil
+}
+
+// mergeKeys applies the DuplicateKeys policy to the JSON text data the lexer puts out,
+// keys are the offsets of its keys in the input. It returns the JSON text to load along with the duplicate keys found,
+// which are passed to DuplicateReport as well.
+func (o Options) mergeKeys(data string, keys []int) (string, []DuplicateKey, error) {
+	if !o.findsDuplicates() {
+		return data, nil, nil
+	}
+	m := keyMerger{d: valueDecoder{data: data}, policy: o.DuplicateKeys, keys: keys}
+	merged, err := m.value()
+	// the loader reports the text which can't be decoded
+	if err != nil || len(m.duplicates) == 0 {
+		return data, nil, nil
+	}
+	if o.DuplicateReport != nil {
+		for _, duplicate := range m.duplicates {
+			o.DuplicateReport(duplicate)
+		}
+	}
+	switch o.DuplicateKeys {
+	case DuplicateError:
+		return "", nil, &DuplicateKeyError{Duplicates: m.duplicates}
+	case DuplicateLast, DuplicateKeepAll:
+		return data, m.duplicates, nil
+	}
+	return merged, m.duplicates, nil
+}
+
+// keyOffsets returns the offsets of the keys of the object the lexer finds in input from position start on,
+// if duplicate keys are looked for.
+func (o Options) keyOffsets(input string, start int) []int {
+	if !o.findsDuplicates() {
+		return nil
+	}
+	// the lexer skips anything before the first bracket
+	i := strings.IndexAny(input[start:], "{[")
+	if i < 0 {
+		return nil
+	}
+	return chompjs.KeyOffsets(input, start+i)
+}
+
+// keyMerger rewrites a JSON text merging the members of its objects which share a key.
+type keyMerger struct {
+	d      valueDecoder
+	policy DuplicateKeys
+	// keys are the offsets of the keys in the input, key is the index of the next one.
+	keys       []int
+	key        int
+	duplicates []DuplicateKey
+}
+
+// mergedMember is a member of an object along with the values of the members sharing its key.
+type mergedMember struct {
+	// key is the JSON text of the key up to the colon.
+	key    string
+	values []string
+}
+
+// value returns the JSON text of the value at the current position, with the members of its objects merged.
+func (m *keyMerger) value() (string, error) {
+	d := &m.d
+	if d.skipSpaces(); d.i < len(d.data) {
+		switch d.data[d.i] {
+		case '{':
+			return m.object()
+		case '[':
+			return m.array()
+		}
+	}
+	start := d.i
+	if _, err := d.value(); err != nil {
+		return "", err
+	}
+	return d.data[start:d.i], nil
+}
+
+func (m *keyMerger) object() (string, error) {
+	d := &m.d
+	// skips {
+	d.i++
+	if d.skipSpaces(); d.i < len(d.data) && d.data[d.i] == '}' {
+		d.i++
+		return "{}", nil
+	}
+	var members []mergedMember
+	index := make(map[string]int)
+	for {
+		if d.skipSpaces(); d.i >= len(d.data) || d.data[d.i] != '"' {
+			return "", d.syntaxError("looking for a key")
+		}
+		start := d.i
+		key, err := d.string()
+		if err != nil {
+			return "", err
+		}
+		if d.skipSpaces(); d.i >= len(d.data) || d.data[d.i] != ':' {
+			return "", d.syntaxError("after a key")
+		}
+		d.i++
+		offset := -1
+		if m.key < len(m.keys) {
+			offset = m.keys[m.key]
+		}
+		m.key++
+		j, found := index[key]
+		if found {
+			m.duplicates = append(m.duplicates, DuplicateKey{Key: key, Offset: offset})
+		} else {
+			j = len(members)
+			index[key] = j
+			members = append(members, mergedMember{key: d.data[start:d.i]})
+		}
+		value, err := m.value()
+		if err != nil {
+			return "", err
+		}
+		members[j].values = append(members[j].values, value)
+		if done, err := d.next('}'); err != nil {
+			return "", err
+		} else if done {
+			break
+		}
+	}
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, member := range members {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(member.key)
+		b.WriteString(m.merge(member.values))
+	}
+	b.WriteByte('}')
+	return b.String(), nil
+}
+
+func (m *keyMerger) array() (string, error) {
+	d := &m.d
+	// skips [
+	d.i++
+	if d.skipSpaces(); d.i < len(d.data) && d.data[d.i] == ']' {
+		d.i++
+		return "[]", nil
+	}
+	var items []string
+	for {
+		item, err := m.value()
+		if err != nil {
+			return "", err
+		}
+		items = append(items, item)
+		if done, err := d.next(']'); err != nil {
+			return "", err
+		} else if done {
+			return "[" + strings.Join(items, ",") + "]", nil
+		}
+	}
+}
+
+// merge returns the JSON text of the value of the members sharing a key, their values are in the order of the input.
+func (m *keyMerger) merge(values []string) string {
+	switch {
+	case len(values) == 1 || m.policy == DuplicateFirst:
+		return values[0]
+	case m.policy == DuplicateCollect:
+		return "[" + strings.Join(values, ",") + "]"
+	}
+	return values[len(values)-1]
+}
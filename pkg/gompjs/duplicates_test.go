@@ -0,0 +1,272 @@
+package gompjs
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+)
+
+func TestWithDuplicateKeys(t *testing.T) {
+	input := `var product = {price: 1, name: 'a', "price": 2, specs: {size: 'S', 'size': 'M', size: 'L'}};`
+	tests := []struct {
+		name       string
+		duplicates DuplicateKeys
+		want       any
+	}{
+		{
+			name:       "last value",
+			duplicates: DuplicateLast,
+			want:       map[string]any{"price": 2.0, "name": "a", "specs": map[string]any{"size": "L"}},
+		},
+		{
+			name:       "every value",
+			duplicates: DuplicateKeepAll,
+			want:       map[string]any{"price": 2.0, "name": "a", "specs": map[string]any{"size": "L"}},
+		},
+		{
+			name:       "first value",
+			duplicates: DuplicateFirst,
+			want:       map[string]any{"price": 1.0, "name": "a", "specs": map[string]any{"size": "S"}},
+		},
+		{
+			name:       "collected values",
+			duplicates: DuplicateCollect,
+			want:       map[string]any{"price": []any{1.0, 2.0}, "name": "a", "specs": map[string]any{"size": []any{"S", "M", "L"}}},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(&input, WithDuplicateKeys(tt.duplicates))
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDuplicateKeyError(t *testing.T) {
+	input := `var product = {price: 1, name: 'a', "price": 2, specs: {size: 'S', 'size': 'M', size: 'L'}};`
+	_, err := Parse(&input, WithDuplicateKeys(DuplicateError))
+	var duplicateErr *DuplicateKeyError
+	if !errors.As(err, &duplicateErr) {
+		t.Fatalf("Parse() error = %v, want *DuplicateKeyError", err)
+	}
+	want := []DuplicateKey{
+		{Key: "price", Offset: strings.Index(input, `"price"`)},
+		{Key: "size", Offset: strings.Index(input, `'size'`)},
+		{Key: "size", Offset: strings.LastIndex(input, "size")},
+	}
+	if !reflect.DeepEqual(duplicateErr.Duplicates, want) {
+		t.Errorf("Duplicates = %+v, want %+v", duplicateErr.Duplicates, want)
+	}
+	if got, want := err.Error(), `duplicate key "price" at offset 36 and 2 more`; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+	input = `{price: 1, prices: [1, 2]}`
+	if _, err := Parse(&input, WithDuplicateKeys(DuplicateError)); err != nil {
+		t.Errorf("Parse() error = %v for an object without duplicate keys", err)
+	}
+}
+
+func TestDuplicateKeysStream(t *testing.T) {
+	input := "{a: 1, a: 2} {'b': [{c: 1}], d: 3} {e: function() { return {f: 1, f: 2} }, 'e': \"x: y\", e: null}"
+	dataChannel, errChannel := ParseAll(context.Background(), &input, WithDuplicateKeys(DuplicateError), WithLoadErrors())
+	got, errs := collectErrors(dataChannel, errChannel)
+	if want := []any{map[string]any{"b": []any{map[string]any{"c": 1.0}}, "d": 3.0}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("data = %v, want %v", got, want)
+	}
+	wantErrs := []struct {
+		object     int
+		duplicates []DuplicateKey
+	}{
+		{0, []DuplicateKey{{"a", 7}}},
+		{strings.Index(input, "{e:"), []DuplicateKey{{"e", strings.Index(input, "'e'")}, {"e", strings.LastIndex(input, "e:")}}},
+	}
+	if len(errs) != len(wantErrs) {
+		t.Fatalf("errors = %v, want %d", errs, len(wantErrs))
+	}
+	for i, want := range wantErrs {
+		var decodeErr *DecodeError
+		var duplicateErr *DuplicateKeyError
+		if !errors.As(errs[i], &decodeErr) || !errors.As(errs[i], &duplicateErr) {
+			t.Fatalf("errors[%d] = %v, want *DecodeError of *DuplicateKeyError", i, errs[i])
+		}
+		if decodeErr.Offset != want.object || !reflect.DeepEqual(duplicateErr.Duplicates, want.duplicates) {
+			t.Errorf("errors[%d] = %d %+v, want %d %+v", i, decodeErr.Offset, duplicateErr.Duplicates, want.object, want.duplicates)
+		}
+	}
+}
+
+func TestDuplicateKeysObjects(t *testing.T) {
+	input := "<script>\nvar a = [{id: 1, id: 2}, {id: 3}];\n</script><script>b = {x: [], x: {}, x: 0}</script>"
+	want := []Object{
+		{
+			Value:      []any{map[string]any{"id": 1.0}, map[string]any{"id": 3.0}},
+			Span:       Span{Start: 17, End: 42, Line: 2, Column: 9},
+			Duplicates: []DuplicateKey{{"id", 26}},
+		},
+		{
+			Value:      map[string]any{"x": []any{}},
+			Span:       Span{Start: 65, End: 85, Line: 3, Column: 22},
+			Duplicates: []DuplicateKey{{"x", 73}, {"x", 80}},
+		},
+	}
+	sources := map[string]func() (<-chan Object, <-chan error){
+		"string": func() (<-chan Object, <-chan error) {
+			return ParseAllObjects(context.Background(), &input, WithDuplicateKeys(DuplicateFirst))
+		},
+		"reader": func() (<-chan Object, <-chan error) {
+			return ParseReaderObjects(context.Background(), iotest.OneByteReader(strings.NewReader(input)), WithDuplicateKeys(DuplicateFirst))
+		},
+	}
+	for name, parse := range sources {
+		parse := parse
+		t.Run(name, func(t *testing.T) {
+			dataChannel, errChannel := parse()
+			var got []Object
+			for object := range dataChannel {
+				got = append(got, object)
+			}
+			if err := <-errChannel; err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("objects = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestDuplicateKeysOrdered(t *testing.T) {
+	input := `{b: 1, a: {c: 1}, b: 2, a: {c: 2, c: 3}}`
+	got, err := Parse(&input, WithOrderedKeys(), WithDuplicateKeys(DuplicateCollect))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	want := OrderedMap{
+		{"b", []any{1.0, 2.0}},
+		{"a", []any{OrderedMap{{"c", 1.0}}, OrderedMap{{"c", []any{2.0, 3.0}}}}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse() = %v, want %v", got, want)
+	}
+}
+
+func TestWithDuplicateReport(t *testing.T) {
+	input := `var product = {price: 1, name: 'a', "price": 2, specs: {size: 'S', 'size': 'M', size: 'L'}};`
+	want := []DuplicateKey{
+		{Key: "price", Offset: strings.Index(input, `"price"`)},
+		{Key: "size", Offset: strings.Index(input, `'size'`)},
+		{Key: "size", Offset: strings.LastIndex(input, "size")},
+	}
+	parsers := map[string]func(opts ...Option) error{
+		"Parse": func(opts ...Option) error {
+			_, err := Parse(&input, opts...)
+			return err
+		},
+		"ParseAs": func(opts ...Option) error {
+			_, err := ParseAs[map[string]any](&input, opts...)
+			return err
+		},
+		"ParseValue": func(opts ...Option) error {
+			_, err := ParseValue(&input, opts...)
+			return err
+		},
+	}
+	for name, parse := range parsers {
+		parse := parse
+		for _, duplicates := range []DuplicateKeys{DuplicateLast, DuplicateFirst, DuplicateError} {
+			duplicates := duplicates
+			t.Run(name, func(t *testing.T) {
+				var got []DuplicateKey
+				report := WithDuplicateReport(func(duplicate DuplicateKey) {
+					got = append(got, duplicate)
+				})
+				err := parse(WithDuplicateKeys(duplicates), report)
+				if (err != nil) != (duplicates == DuplicateError) {
+					t.Fatalf("%s() error = %v with policy %d", name, err, duplicates)
+				}
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("reported %+v with policy %d, want %+v", got, duplicates, want)
+				}
+			})
+		}
+	}
+}
+
+func TestDuplicateReportObjects(t *testing.T) {
+	input := "<script>\nvar a = [{id: 1, id: 2}, {id: 3}];\n</script><script>b = {x: [], x: {}, x: 0}</script>"
+	var reported []DuplicateKey
+	report := WithDuplicateReport(func(duplicate DuplicateKey) {
+		reported = append(reported, duplicate)
+	})
+	dataChannel, errChannel := ParseAllObjects(context.Background(), &input, report)
+	var got [][]DuplicateKey
+	for object := range dataChannel {
+		got = append(got, object.Duplicates)
+	}
+	if err := <-errChannel; err != nil {
+		t.Fatalf("error = %v", err)
+	}
+	if want := [][]DuplicateKey{{{"id", 26}}, {{"x", 73}, {"x", 80}}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Duplicates = %+v, want %+v", got, want)
+	}
+	if want := []DuplicateKey{{"id", 26}, {"x", 73}, {"x", 80}}; !reflect.DeepEqual(reported, want) {
+		t.Errorf("reported %+v, want %+v", reported, want)
+	}
+}
+
+func TestDuplicateKeysStreamSkipped(t *testing.T) {
+	input := "{a: 1, a: 2} {b: 1} {c: 1, c: 2}"
+	dataChannel, errChannel := ParseAll(context.Background(), &input, WithDuplicateKeys(DuplicateError))
+	got, errs := collectErrors(dataChannel, errChannel)
+	if want := []any{map[string]any{"b": 1.0}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("data = %v, want %v", got, want)
+	}
+	if len(errs) != 0 {
+		t.Errorf("errors = %v, want none without WithLoadErrors", errs)
+	}
+	typed, errChannel := ParseAllAs[map[string]int](context.Background(), &input, WithDuplicateKeys(DuplicateError))
+	var gotTyped []map[string]int
+	for element := range typed {
+		gotTyped = append(gotTyped, element)
+	}
+	for err := range errChannel {
+		t.Errorf("ParseAllAs() error = %v, want none without WithLoadErrors", err)
+	}
+	if want := []map[string]int{{"b": 1}}; !reflect.DeepEqual(gotTyped, want) {
+		t.Errorf("ParseAllAs() = %v, want %v", gotTyped, want)
+	}
+}
+
+func TestDuplicateKeysUnexpectedBracket(t *testing.T) {
+	policies := []DuplicateKeys{DuplicateLast, DuplicateKeepAll, DuplicateFirst, DuplicateError, DuplicateCollect}
+	for _, input := range []string{"var x = {a: b>c};", "var x = {a: f)};"} {
+		for _, policy := range policies {
+			input, policy := input, policy
+			t.Run(input, func(t *testing.T) {
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				report := WithDuplicateReport(func(DuplicateKey) {})
+				dataChannel, errChannel := ParseAll(ctx, &input, WithDuplicateKeys(policy), report)
+				for range dataChannel {
+				}
+				for err := range errChannel {
+					if errors.Is(err, context.DeadlineExceeded) {
+						t.Fatalf("ParseAll() doesn't finish with policy %d", policy)
+					}
+				}
+				// returns, whatever the object is
+				_, _ = Parse(&input, WithDuplicateKeys(policy), report)
+			})
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package gompjs
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/proway2/gompjs/internal/chompjs"
+)
+
+// Kinds of parse errors, a *ParseError matches its kind with errors.Is.
+var (
+	// ErrNoObject means there's no object in the input at all, e.g. the page has changed its layout.
+	ErrNoObject = chompjs.ErrNoObject
+	// ErrUnexpectedEOF means the input ends in the middle of an object, e.g. the download is truncated.
+	ErrUnexpectedEOF = chompjs.ErrUnexpectedEOF
+	// ErrUnterminatedString means the input ends in the middle of a string.
+	ErrUnterminatedString = chompjs.ErrUnterminatedString
+	// ErrUnexpectedClosingBracket means there's a stray ')' or '>' where a value is expected.
+	ErrUnexpectedClosingBracket = chompjs.ErrUnexpectedClosingBracket
+	// ErrInvalidNumber means there's a malformed number, e.g. a minus sign followed by a letter.
+	ErrInvalidNumber = chompjs.ErrInvalidNumber
+)
+
+// ParseError reports why and where the input can't be parsed.
+// Positions refer to the input the lexer gets, i.e. after unicode escape sequences are decoded.
+type ParseError struct {
+	// Kind is one of the kinds of parse errors, such as ErrUnterminatedString.
+	Kind error
+	// Offset is the byte offset of the input the error is found at.
+	Offset int
+	// Line is the 1-based line number of Offset.
+	Line int
+	// Column is the 1-based column of Offset, counted in characters.
+	Column int
+	// Snippet is the input around Offset.
+	Snippet string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("error parsing input at line %d, column %d (character %d): %v near %q", e.Line, e.Column, e.Offset, e.Kind, e.Snippet)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Kind
+}
+
+// newParseError converts a lexer error into *ParseError, other errors are returned as they are.
+func newParseError(err error) error {
+	var lexerErr *chompjs.Error
+	if !errors.As(err, &lexerErr) {
+		return err
+	}
+	return &ParseError{
+		Kind:    lexerErr.Kind,
+		Offset:  lexerErr.Offset,
+		Line:    lexerErr.Line,
+		Column:  lexerErr.Column,
+		Snippet: lexerErr.Snippet,
+	}
+}
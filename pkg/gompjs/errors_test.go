@@ -0,0 +1,247 @@
+package gompjs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+)
+
+func TestParseError(t *testing.T) {
+	tests := []struct {
+		name     string
+		inputStr string
+		kind     error
+		offset   int
+		line     int
+		column   int
+		snippet  string
+	}{
+		{
+			name:     "Unterminated string",
+			inputStr: `{"test": """}`,
+			kind:     ErrUnterminatedString,
+			offset:   13,
+			line:     1,
+			column:   14,
+			snippet:  `{"test": """}`,
+		},
+		{
+			name:     "Unterminated string on another line",
+			inputStr: "var a = {\n  'a': 1,\n  'b': 'zażółć",
+			kind:     ErrUnterminatedString,
+			offset:   38,
+			line:     3,
+			column:   15,
+			snippet:  "var a = {\n  'a': 1,\n  'b': 'zażółć",
+		},
+		{
+			name:     "Unexpected closing bracket",
+			inputStr: "{a: 1, b: )}",
+			kind:     ErrUnexpectedClosingBracket,
+			offset:   10,
+			line:     1,
+			column:   11,
+			snippet:  "{a: 1, b: )}",
+		},
+		{
+			name:     "Invalid number",
+			inputStr: "[1, 2, -x]",
+			kind:     ErrInvalidNumber,
+			offset:   8,
+			line:     1,
+			column:   9,
+			snippet:  "[1, 2, -x]",
+		},
+		{
+			name:     "Truncated input",
+			inputStr: "{'a': [1, 2",
+			kind:     ErrUnexpectedEOF,
+			offset:   11,
+			line:     1,
+			column:   12,
+			snippet:  "{'a': [1, 2",
+		},
+		{
+			name:     "Truncated input after a comma",
+			inputStr: "}{",
+			kind:     ErrUnexpectedEOF,
+			offset:   2,
+			line:     1,
+			column:   3,
+			snippet:  "}{",
+		},
+		{
+			name:     "No object",
+			inputStr: "<html><p>Not found</p></html>",
+			kind:     ErrNoObject,
+			offset:   29,
+			line:     1,
+			column:   30,
+			snippet:  "<html><p>Not found</p></html>",
+		},
+		{
+			name:     "Empty input",
+			inputStr: "",
+			kind:     ErrNoObject,
+			line:     1,
+			column:   1,
+		},
+		{
+			name:     "Long input",
+			inputStr: "['0123456789012345678901234567890123456789', 'ąćęłńóśźż012345678901234567890123456789', -]",
+			kind:     ErrInvalidNumber,
+			offset:   98,
+			line:     1,
+			column:   90,
+			snippet:  "śźż012345678901234567890123456789', -]",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(&tt.inputStr)
+			if !errors.Is(err, tt.kind) {
+				t.Fatalf("Parse() error = %v, want %v", err, tt.kind)
+			}
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("Parse() error = %v, want *ParseError", err)
+			}
+			if parseErr.Offset != tt.offset || parseErr.Line != tt.line || parseErr.Column != tt.column {
+				t.Errorf("Parse() error at %d (%d:%d), want %d (%d:%d)", parseErr.Offset, parseErr.Line, parseErr.Column, tt.offset, tt.line, tt.column)
+			}
+			if parseErr.Snippet != tt.snippet {
+				t.Errorf("ParseError.Snippet = %q, want %q", parseErr.Snippet, tt.snippet)
+			}
+		})
+	}
+}
+
+// collectErrors reads both channels until they are closed, keeping every error.
+func collectErrors(dataChannel <-chan any, errChannel <-chan error) ([]any, []error) {
+	var got []any
+	var errs []error
+	for dataChannel != nil || errChannel != nil {
+		select {
+		case data, ok := <-dataChannel:
+			if !ok {
+				dataChannel = nil
+				continue
+			}
+			got = append(got, data)
+		case err, ok := <-errChannel:
+			if !ok {
+				errChannel = nil
+				continue
+			}
+			errs = append(errs, err)
+		}
+	}
+	return got, errs
+}
+
+func TestParseAllParseErrors(t *testing.T) {
+	input := "[1] {a: )}\n[2] {'b': [3, -x, {c: 1}]} [4] {'d': 'unterminated"
+	wantData := []any{[]any{1.0}, []any{2.0}, []any{4.0}}
+	wantErrs := []struct {
+		kind         error
+		offset       int
+		line, column int
+	}{
+		{ErrUnexpectedClosingBracket, 8, 1, 9},
+		{ErrInvalidNumber, 26, 2, 16},
+		{ErrUnterminatedString, 61, 2, 51},
+	}
+	sources := map[string]func() (<-chan any, <-chan error){
+		"string": func() (<-chan any, <-chan error) {
+			return ParseAll(context.Background(), &input, WithParseErrors())
+		},
+		"one byte reader": func() (<-chan any, <-chan error) {
+			return ParseReader(context.Background(), iotest.OneByteReader(strings.NewReader(input)), WithParseErrors())
+		},
+		"half reader": func() (<-chan any, <-chan error) {
+			return ParseReader(context.Background(), iotest.HalfReader(strings.NewReader(input)), WithParseErrors())
+		},
+	}
+	for name, parse := range sources {
+		parse := parse
+		t.Run(name, func(t *testing.T) {
+			got, errs := collectErrors(parse())
+			if !reflect.DeepEqual(got, wantData) {
+				t.Errorf("data = %v, want %v", got, wantData)
+			}
+			if len(errs) != len(wantErrs) {
+				t.Fatalf("errors = %v, want %d errors", errs, len(wantErrs))
+			}
+			for i, want := range wantErrs {
+				var parseErr *ParseError
+				if !errors.As(errs[i], &parseErr) || !errors.Is(parseErr, want.kind) {
+					t.Fatalf("error %d = %v, want %v", i, errs[i], want.kind)
+				}
+				if parseErr.Offset != want.offset || parseErr.Line != want.line || parseErr.Column != want.column {
+					t.Errorf("error %d at %d (%d:%d), want %d (%d:%d)", i, parseErr.Offset, parseErr.Line, parseErr.Column, want.offset, want.line, want.column)
+				}
+			}
+		})
+	}
+}
+
+func TestParseAllWithoutParseErrors(t *testing.T) {
+	input := "[1] {a: )} [2]"
+	for name, r := range map[string]io.Reader{"string": nil, "reader": strings.NewReader(input)} {
+		var got []any
+		var errs []error
+		if r == nil {
+			got, errs = collectErrors(ParseAll(context.Background(), &input))
+		} else {
+			got, errs = collectErrors(ParseReader(context.Background(), r))
+		}
+		if len(errs) != 0 {
+			t.Errorf("%s: errors = %v, want none", name, errs)
+		}
+		// just as chompjs does, parsing stops at the malformed object
+		if want := []any{[]any{1.0}}; !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: data = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestParseAllErrorsAfterData(t *testing.T) {
+	inputs := map[string]struct {
+		input string
+		opts  []Option
+	}{
+		"parse errors": {"}-0o7[)11NaNNaN]'//{ [1] {a: )} [2] {b: -x}", []Option{WithParseErrors()}},
+		"load errors":  {"[1] {a: NaN} [2] {b: NaN} [3] {c: NaN}", []Option{WithLoadErrors()}},
+	}
+	for name, tt := range inputs {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			done := make(chan []error)
+			go func() {
+				// the data channel is read until it's closed, only then the error channel
+				dataChannel, errChannel := ParseAll(context.Background(), &tt.input, tt.opts...)
+				for range dataChannel {
+				}
+				var errs []error
+				for err := range errChannel {
+					errs = append(errs, err)
+				}
+				done <- errs
+			}()
+			select {
+			case errs := <-done:
+				if len(errs) < 2 {
+					t.Errorf("errors = %v, want at least 2", errs)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("reading the data channel before the error channel blocks")
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package gompjs
+
+import (
+	"context"
+	"html"
+	"strings"
+
+	"github.com/proway2/gompjs/internal/chompjs"
+)
+
+// Script describes a script element of an HTML document.
+type Script struct {
+	// Index is the index of the element among all the script elements of the document, whatever their types.
+	Index int
+	// ID is the value of its id attribute.
+	ID string
+	// Type is the value of its type attribute, empty if there's none.
+	Type string
+	// Start and End are the byte offsets of its contents, so input[Start:End] is the script.
+	Start, End int
+}
+
+// ScriptObject is an object parsed from a script element of an HTML document.
+type ScriptObject struct {
+	Value  any
+	Span   Span
+	Script Script
+	// Duplicates are the duplicate keys of the object, see Object.
+	Duplicates []DuplicateKey
+}
+
+// ParseHTML is ParseAll parsing objects only from the contents of the script elements of an HTML document,
+// so brackets of CSS blocks, attributes and text aren't taken for objects. Each script is lexed on its own,
+// positions of the objects refer to the whole document. The scripts can be filtered by type with WithScriptTypes.
+//
+// The document is split into scripts with a tokenizer which only knows about tags, comments and raw text elements,
+// scripts aren't required to be valid HTML.
+func ParseHTML(ctx context.Context, inputStr *string, opts ...Option) (<-chan ScriptObject, <-chan error) {
+	o := newOptions(opts)
+	var scripts []Script
+	split := func(input string) []chompjs.Part {
+		var parts []chompjs.Part
+		for _, script := range findScripts(input) {
+			if o.scriptType(script.Type) {
+				scripts = append(scripts, script)
+				parts = append(parts, chompjs.Part{Start: script.Start, End: script.End})
+			}
+		}
+		return parts
+	}
+	load := loadObject(o)
+	return streamParts(ctx, inputStr, o, split, func(object *chompjs.Object) (ScriptObject, bool, error) {
+		element, keep, err := load(object)
+		return ScriptObject{Value: element.Value, Span: element.Span, Script: scripts[object.Part], Duplicates: element.Duplicates}, keep, err
+	})
+}
+
+// scriptType tells whether the scripts of type t are parsed.
+func (o Options) scriptType(t string) bool {
+	if len(o.ScriptTypes) == 0 {
+		return true
+	}
+	t = mediaType(t)
+	for _, want := range o.ScriptTypes {
+		if mediaType(want) == t {
+			return true
+		}
+	}
+	return false
+}
+
+// mediaType normalizes the type of a script, which is JavaScript unless set.
+func mediaType(t string) string {
+	if i := strings.IndexByte(t, ';'); i >= 0 {
+		t = t[:i]
+	}
+	t = strings.ToLower(strings.TrimSpace(t))
+	if t == "" {
+		return "text/javascript"
+	}
+	return t
+}
+
+// rawTextElements hold text which may look like tags, it lasts until their end tag.
+var rawTextElements = map[string]bool{
+	"script":   true,
+	"style":    true,
+	"textarea": true,
+	"title":    true,
+}
+
+// findScripts returns every script element of an HTML document.
+func findScripts(input string) []Script {
+	var scripts []Script
+	for i := 0; i < len(input); {
+		next := strings.IndexByte(input[i:], '<')
+		if next < 0 {
+			break
+		}
+		i += next
+		switch {
+		case strings.HasPrefix(input[i:], "<!--"):
+			end := strings.Index(input[i+4:], "-->")
+			if end < 0 {
+				return scripts
+			}
+			i += 4 + end + 3
+		case i+1 < len(input) && isLetter(input[i+1]):
+			var name string
+			var attrs map[string]string
+			name, attrs, i = readTag(input, i+1)
+			if !rawTextElements[name] {
+				continue
+			}
+			end := indexEndTag(input, i, name)
+			if name == "script" {
+				scripts = append(scripts, Script{
+					Index: len(scripts),
+					ID:    attrs["id"],
+					Type:  attrs["type"],
+					Start: i,
+					End:   end,
+				})
+			}
+			i = end
+		default:
+			i++
+		}
+	}
+	return scripts
+}
+
+// readTag reads the start tag whose name begins at position i of input.
+// It returns the lowercased tag name, its attributes and the position right after the tag.
+func readTag(input string, i int) (string, map[string]string, int) {
+	start := i
+	for i < len(input) && !isTagSpace(input[i]) && input[i] != '/' && input[i] != '>' {
+		i++
+	}
+	name := strings.ToLower(input[start:i])
+	attrs := map[string]string{}
+	for i < len(input) {
+		for i < len(input) && (isTagSpace(input[i]) || input[i] == '/') {
+			i++
+		}
+		if i >= len(input) {
+			break
+		}
+		if input[i] == '>' {
+			return name, attrs, i + 1
+		}
+		start = i
+		for i < len(input) && !isTagSpace(input[i]) && input[i] != '/' && input[i] != '>' && input[i] != '=' {
+			i++
+		}
+		// an attribute name may start with '='
+		if i == start {
+			i++
+		}
+		attr := strings.ToLower(input[start:i])
+		for i < len(input) && isTagSpace(input[i]) {
+			i++
+		}
+		value := ""
+		if i < len(input) && input[i] == '=' {
+			i++
+			for i < len(input) && isTagSpace(input[i]) {
+				i++
+			}
+			start = i
+			if i < len(input) && (input[i] == '"' || input[i] == '\'') {
+				end := strings.IndexByte(input[i+1:], input[i])
+				if end < 0 {
+					return name, attrs, len(input)
+				}
+				value = input[i+1 : i+1+end]
+				i += 1 + end + 1
+			} else {
+				for i < len(input) && !isTagSpace(input[i]) && input[i] != '>' {
+					i++
+				}
+				value = input[start:i]
+			}
+		}
+		// just as browsers do, the first of the duplicate attributes is kept
+		if _, ok := attrs[attr]; !ok {
+			attrs[attr] = html.UnescapeString(value)
+		}
+	}
+	return name, attrs, len(input)
+}
+
+// indexEndTag returns the position of the end tag of the element name starting the search at position i of input,
+// or len(input) if there's none.
+func indexEndTag(input string, i int, name string) int {
+	for {
+		next := strings.Index(input[i:], "</")
+		if next < 0 {
+			return len(input)
+		}
+		i += next
+		end := i + 2 + len(name)
+		if end <= len(input) && strings.EqualFold(input[i+2:end], name) &&
+			(end == len(input) || isTagSpace(input[end]) || input[end] == '/' || input[end] == '>') {
+			return i
+		}
+		i += 2
+	}
+}
+
+func isLetter(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+// isTagSpace tells whether c is ASCII whitespace, as HTML defines it.
+func isTagSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\f' || c == '\r'
+}
@@ -0,0 +1,84 @@
+package gompjs
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+const page = `<html><head>
+<style>body { color: red; } a[href] { color: blue }</style>
+<title>[not an object]</title>
+<!-- <script>{"commented": true}</script> -->
+<script SRC="/app.js"></script>
+<script type="application/json" id="config" data-x='<script>{"quoted": 1}</script>'>{"a": 1}</script>
+<script type="text/template">{{ name }}</script>
+</head><body data-state="{&quot;b&quot;: 2}">
+<p>[1, 2]</p>
+<SCRIPT>window.state = {b: 2, c: [3]}; var s = "</scripts>"</SCRIPT>
+</body></html>`
+
+func parseHTML(t *testing.T, opts ...Option) []ScriptObject {
+	t.Helper()
+	input := page
+	dataChannel, errChannel := ParseHTML(context.Background(), &input, opts...)
+	var got []ScriptObject
+	for object := range dataChannel {
+		got = append(got, object)
+	}
+	if err := <-errChannel; err != nil {
+		t.Fatalf("ParseHTML() error = %v", err)
+	}
+	for _, object := range got {
+		if object.Span.Start < object.Script.Start || object.Span.End > object.Script.End {
+			t.Errorf("ParseHTML() object at %+v is not in its script %+v", object.Span, object.Script)
+		}
+	}
+	return got
+}
+
+func TestParseHTML(t *testing.T) {
+	got := parseHTML(t)
+	if len(got) != 2 {
+		t.Fatalf("ParseHTML() = %+v, want 2 objects", got)
+	}
+	if want := map[string]any{"a": 1.0}; !reflect.DeepEqual(got[0].Value, want) {
+		t.Errorf("ParseHTML() value = %v, want %v", got[0].Value, want)
+	}
+	if script := got[0].Script; script.Index != 1 || script.ID != "config" || script.Type != "application/json" {
+		t.Errorf("ParseHTML() script = %+v, want the config script", script)
+	}
+	if raw := page[got[0].Span.Start:got[0].Span.End]; raw != `{"a": 1}` {
+		t.Errorf("ParseHTML() span = %q", raw)
+	}
+	if want := map[string]any{"b": 2.0, "c": []any{3.0}}; !reflect.DeepEqual(got[1].Value, want) {
+		t.Errorf("ParseHTML() value = %v, want %v", got[1].Value, want)
+	}
+	if script := got[1].Script; script.Index != 3 || script.ID != "" || script.Type != "" {
+		t.Errorf("ParseHTML() script = %+v, want the last script", script)
+	}
+	if span := got[1].Span; span.Line != 10 || span.Column != 24 {
+		t.Errorf("ParseHTML() span = %+v, want line 10, column 24", span)
+	}
+}
+
+func TestParseHTMLScriptTypes(t *testing.T) {
+	tests := []struct {
+		types []string
+		want  []int
+	}{
+		{types: []string{"application/json"}, want: []int{1}},
+		{types: []string{"TEXT/JavaScript; charset=utf-8"}, want: []int{3}},
+		{types: []string{"text/template", "application/json"}, want: []int{1}},
+		{types: []string{"module"}, want: nil},
+	}
+	for _, tt := range tests {
+		var got []int
+		for _, object := range parseHTML(t, WithScriptTypes(tt.types...)) {
+			got = append(got, object.Script.Index)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseHTML(WithScriptTypes(%q)) scripts = %v, want %v", tt.types, got, tt.want)
+		}
+	}
+}
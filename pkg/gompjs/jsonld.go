@@ -0,0 +1,185 @@
+package gompjs
+
+import (
+	"context"
+	"encoding/json"
+	"html"
+	"sort"
+	"strings"
+)
+
+// jsonLDType is the type of the script elements holding JSON-LD.
+const jsonLDType = "application/ld+json"
+
+// ExtractJSONLD returns the nodes of every JSON-LD script element of an HTML document, `<script type="application/ld+json">`.
+// Each block is repaired by the lexer, so trailing commas, unquoted keys and raw newlines in strings are accepted,
+// and HTML entities such as "&amp;" are decoded in strings. A block holding a single node, an array of nodes
+// or an "@graph" array gives its nodes one by one, in the order they are found.
+//
+// If types are given, only the nodes whose "@type" is one of them are returned, nested nodes included,
+// so "Offer" finds the offers of a product as well.
+//
+// Blocks which can't be parsed are skipped, the first error is returned along with the nodes of the other blocks.
+func ExtractJSONLD(inputStr *string, types ...string) ([]map[string]any, error) {
+	dataChannel, errChannel := ParseHTML(context.Background(), inputStr,
+		WithScriptTypes(jsonLDType),
+		WithParseErrors(),
+		WithLoadErrors(),
+		WithLoader(unmarshalJSONLD),
+	)
+	var nodes []map[string]any
+	var firstErr error
+	for dataChannel != nil || errChannel != nil {
+		select {
+		case object, ok := <-dataChannel:
+			if !ok {
+				dataChannel = nil
+				continue
+			}
+			nodes = appendJSONLDNodes(nodes, unescapeEntities(object.Value))
+		case err, ok := <-errChannel:
+			if !ok {
+				errChannel = nil
+				continue
+			}
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	if len(types) > 0 {
+		var matching []map[string]any
+		for _, node := range nodes {
+			matching = appendTypedNodes(matching, node, types)
+		}
+		nodes = matching
+	}
+	return nodes, firstErr
+}
+
+// unmarshalJSONLD is json.Unmarshal accepting control characters in strings.
+func unmarshalJSONLD(data []byte, v any) error {
+	return json.Unmarshal(escapeControlCharacters(data), v)
+}
+
+// escapeControlCharacters escapes the control characters found in the strings of the JSON document data,
+// which JavaScript doesn't allow either but browsers accept in JSON-LD.
+func escapeControlCharacters(data []byte) []byte {
+	var escaped []byte
+	inString := false
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		if inString && c < 0x20 {
+			if escaped == nil {
+				escaped = append(make([]byte, 0, len(data)+16), data[:i]...)
+			}
+			switch c {
+			case '\n':
+				escaped = append(escaped, `\n`...)
+			case '\r':
+				escaped = append(escaped, `\r`...)
+			case '\t':
+				escaped = append(escaped, `\t`...)
+			default:
+				const hex = "0123456789abcdef"
+				escaped = append(escaped, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xf])
+			}
+			continue
+		}
+		if escaped != nil {
+			escaped = append(escaped, c)
+		}
+		switch {
+		case c == '"':
+			inString = !inString
+		case c == '\\' && inString && i+1 < len(data):
+			// the escaped character can't end the string
+			i++
+			if escaped != nil {
+				escaped = append(escaped, data[i])
+			}
+		}
+	}
+	if escaped == nil {
+		return data
+	}
+	return escaped
+}
+
+// unescapeEntities decodes the HTML entities of the strings of value.
+func unescapeEntities(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, element := range v {
+			v[key] = unescapeEntities(element)
+		}
+	case []any:
+		for i, element := range v {
+			v[i] = unescapeEntities(element)
+		}
+	case string:
+		if strings.IndexByte(v, '&') >= 0 {
+			return html.UnescapeString(v)
+		}
+	}
+	return value
+}
+
+// appendJSONLDNodes appends the nodes of the JSON-LD value to nodes, arrays and "@graph" arrays are flattened.
+func appendJSONLDNodes(nodes []map[string]any, value any) []map[string]any {
+	switch v := value.(type) {
+	case []any:
+		for _, element := range v {
+			nodes = appendJSONLDNodes(nodes, element)
+		}
+	case map[string]any:
+		if graph, ok := v["@graph"].([]any); ok {
+			return appendJSONLDNodes(nodes, graph)
+		}
+		nodes = append(nodes, v)
+	}
+	return nodes
+}
+
+// appendTypedNodes appends value and the values nested in it to nodes, if they are nodes of one of types.
+func appendTypedNodes(nodes []map[string]any, value any, types []string) []map[string]any {
+	switch v := value.(type) {
+	case []any:
+		for _, element := range v {
+			nodes = appendTypedNodes(nodes, element, types)
+		}
+	case map[string]any:
+		if hasJSONLDType(v, types) {
+			nodes = append(nodes, v)
+		}
+		// nested nodes are appended in the order of their keys, maps have none
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			nodes = appendTypedNodes(nodes, v[key], types)
+		}
+	}
+	return nodes
+}
+
+// hasJSONLDType tells whether the "@type" of node, either a string or an array of them, is one of types.
+func hasJSONLDType(node map[string]any, types []string) bool {
+	var nodeTypes []any
+	switch t := node["@type"].(type) {
+	case string:
+		nodeTypes = []any{t}
+	case []any:
+		nodeTypes = t
+	}
+	for _, nodeType := range nodeTypes {
+		for _, want := range types {
+			if nodeType == want {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,114 @@
+package gompjs
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+const jsonLDPage = `<html><head>
+<script type="application/ld+json">
+{
+	"@context": "https://schema.org",
+	"@type": "Product",
+	"name": "Lamp &amp; Shade",
+	"description": "Two lines:
+bright	and warm",
+	"offers": [
+		{"@type": "Offer", "price": "12.50", "priceCurrency": "EUR",},
+		{"@type": ["Offer", "AggregateOffer"], "lowPrice": 10},
+	],
+}
+</script>
+<script type="text/javascript">var product = {"@type": "Product", "name": "not JSON-LD"};</script>
+<script type="Application/LD+JSON">[{"@type": "Organization", "name": "Acme"}, {"@type": "WebSite"}]</script>
+<script type='application/ld+json'>
+{"@context": "https://schema.org", "@graph": [
+	{"@type": "BreadcrumbList", "itemListElement": [{"@type": "ListItem", "position": 1, "name": "Home"}]},
+	{"@type": "WebPage", "name": "Lamp"}
+]}
+</script>
+</head></html>`
+
+func TestExtractJSONLD(t *testing.T) {
+	input := jsonLDPage
+	offer := map[string]any{"@type": "Offer", "price": "12.50", "priceCurrency": "EUR"}
+	aggregateOffer := map[string]any{"@type": []any{"Offer", "AggregateOffer"}, "lowPrice": 10.0}
+	product := map[string]any{
+		"@context":    "https://schema.org",
+		"@type":       "Product",
+		"name":        "Lamp & Shade",
+		"description": "Two lines:\nbright\tand warm",
+		"offers":      []any{offer, aggregateOffer},
+	}
+	breadcrumbs := map[string]any{
+		"@type":           "BreadcrumbList",
+		"itemListElement": []any{map[string]any{"@type": "ListItem", "position": 1.0, "name": "Home"}},
+	}
+	tests := []struct {
+		name  string
+		types []string
+		want  []map[string]any
+	}{
+		{
+			name: "every node",
+			want: []map[string]any{
+				product,
+				{"@type": "Organization", "name": "Acme"},
+				{"@type": "WebSite"},
+				breadcrumbs,
+				{"@type": "WebPage", "name": "Lamp"},
+			},
+		},
+		{
+			name:  "by type",
+			types: []string{"Offer", "BreadcrumbList"},
+			want:  []map[string]any{offer, aggregateOffer, breadcrumbs},
+		},
+		{
+			name:  "no match",
+			types: []string{"Recipe"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExtractJSONLD(&input, tt.types...)
+			if err != nil {
+				t.Fatalf("ExtractJSONLD() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExtractJSONLD() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractJSONLDErrors(t *testing.T) {
+	input := `<script type="application/ld+json">{"@type": "Product", "name": )}</script>
+<script type="application/ld+json">{"@type": "Offer"}</script>`
+	got, err := ExtractJSONLD(&input)
+	if want := []map[string]any{{"@type": "Offer"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ExtractJSONLD() = %v, want %v", got, want)
+	}
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) {
+		t.Errorf("ExtractJSONLD() error = %v, want a *ParseError", err)
+	}
+}
+
+func TestEscapeControlCharacters(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`{"a":"b"}`, `{"a":"b"}`},
+		{"{\"a\":\"b\nc\"}", `{"a":"b\nc"}`},
+		{"{\"a\":\"\\\"\x01\",\n\"b\":1}", "{\"a\":\"\\\"\\u0001\",\n\"b\":1}"},
+	}
+	for _, tt := range tests {
+		if got := string(escapeControlCharacters([]byte(tt.input))); got != tt.want {
+			t.Errorf("escapeControlCharacters(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package gompjs
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/proway2/gompjs/internal/chompjs"
+)
+
+var (
+	// ErrNotJSONP is returned by ParseJSONP for an input which is not a single call of a callback.
+	ErrNotJSONP = errors.New("input is not a JSONP response")
+	// ErrUnexpectedCallback is returned by ParseJSONP if the callback is not the one expected with WithCallback.
+	ErrUnexpectedCallback = errors.New("unexpected JSONP callback")
+)
+
+// JSONP is a parsed JSONP response.
+type JSONP struct {
+	// Callback is the callback called, properties in brackets are written as dotted ones.
+	Callback string
+	// Args are the arguments of the call, loaded just as Parse loads objects.
+	Args []any
+}
+
+// ParseJSONP parses a JSONP response, such as `jQuery112_1699({...});` or `/**/ cb && cb([...])`.
+// The input must be a single call of the callback, optionally preceded by comments and a guard,
+// otherwise ErrNotJSONP is returned. Every argument is parsed, the ones which aren't literals
+// are taken for strings, just as the lexer does. WithCallback checks the name of the callback.
+func ParseJSONP(inputStr *string, opts ...Option) (JSONP, error) {
+	o := newOptions(opts)
+	inputStr, err := lexerInput(o, inputStr)
+	if err != nil {
+		return JSONP{}, err
+	}
+	path, open, close, ok := chompjs.ParseJSONP(*inputStr)
+	if !ok {
+		return JSONP{}, ErrNotJSONP
+	}
+	res := JSONP{Callback: strings.Join(path, ".")}
+	if o.Callback != "" {
+		want := o.Callback
+		if wantPath, ok := chompjs.ParsePath(want); ok {
+			want = strings.Join(wantPath, ".")
+		}
+		if want != res.Callback {
+			return JSONP{}, fmt.Errorf("%w: %s, want %s", ErrUnexpectedCallback, res.Callback, want)
+		}
+	}
+	if res.Args, err = parseArgs(o, *inputStr, open, close); err != nil {
+		return JSONP{}, err
+	}
+	return res, nil
+}
+
+// parseArgs parses the arguments between the parentheses at positions open and close of input as an array.
+func parseArgs(o Options, input string, open, close int) ([]any, error) {
+	parsedString, err := chompjs.FixArgs(&input, open, close)
+	if err != nil {
+		return nil, newParseError(err)
+	}
+	var keys []int
+	if o.findsDuplicates() {
+		keys = chompjs.KeyOffsets(input, open)
+	}
+	data, _, err := o.mergeKeys(*parsedString, keys)
+	if err != nil {
+		return nil, err
+	}
+	if o.OrderedKeys {
+		args, err := o.load(data)
+		if err != nil {
+			return nil, err
+		}
+		return args.([]any), nil
+	}
+	var args []any
+	if err = o.Loader([]byte(data), &args); err != nil {
+		return nil, err
+	}
+	return args, nil
+}
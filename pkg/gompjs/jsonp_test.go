@@ -0,0 +1,81 @@
+package gompjs
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseJSONP(t *testing.T) {
+	tests := []struct {
+		name     string
+		inputStr string
+		want     JSONP
+	}{
+		{
+			name:     "jquery",
+			inputStr: `jQuery112_1699({"a": 1});`,
+			want:     JSONP{Callback: "jQuery112_1699", Args: []any{map[string]any{"a": 1.0}}},
+		},
+		{
+			name:     "guard and comment",
+			inputStr: "/**/ cb && cb([1, 2])",
+			want:     JSONP{Callback: "cb", Args: []any{[]any{1.0, 2.0}}},
+		},
+		{
+			name:     "typeof guard",
+			inputStr: "typeof cb === 'function' && cb({ok: true}, 'x', 3);\n",
+			want:     JSONP{Callback: "cb", Args: []any{map[string]any{"ok": true}, "x", 3.0}},
+		},
+		{
+			name:     "brackets in comments",
+			inputStr: "/* [legacy] {callback} */ window['api'].done(/* (status) */ {s: ')'}, [])",
+			want:     JSONP{Callback: "window.api.done", Args: []any{map[string]any{"s": ")"}, []any{}}},
+		},
+		{
+			name:     "no arguments",
+			inputStr: "cb()",
+			want:     JSONP{Callback: "cb", Args: []any{}},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseJSONP(&tt.inputStr)
+			if err != nil {
+				t.Fatalf("ParseJSONP() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseJSONP() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseJSONPErrors(t *testing.T) {
+	tests := []struct {
+		inputStr string
+		opts     []Option
+		want     error
+	}{
+		{`{"a": 1}`, nil, ErrNotJSONP},
+		{`cb({"a": 1}); other()`, nil, ErrNotJSONP},
+		{`cb({"a": 1}`, nil, ErrNotJSONP},
+		{`cb({"a": 1})`, []Option{WithCallback("jQuery")}, ErrUnexpectedCallback},
+		{`cb({"a": -x})`, nil, ErrInvalidNumber},
+	}
+	for _, tt := range tests {
+		if _, err := ParseJSONP(&tt.inputStr, tt.opts...); !errors.Is(err, tt.want) {
+			t.Errorf("ParseJSONP(%q) error = %v, want %v", tt.inputStr, err, tt.want)
+		}
+	}
+	inputStr := `window["api"].cb([1])`
+	if _, err := ParseJSONP(&inputStr, WithCallback("window.api.cb")); err != nil {
+		t.Errorf("ParseJSONP(%q) error = %v", inputStr, err)
+	}
+	var parseErr *ParseError
+	inputStr = `cb({"a": -x})`
+	if _, err := ParseJSONP(&inputStr); !errors.As(err, &parseErr) || parseErr.Offset != 10 {
+		t.Errorf("ParseJSONP(%q) error = %v, want a *ParseError at 10", inputStr, err)
+	}
+}
@@ -0,0 +1,81 @@
+package gompjs
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseJSONParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		inputStr string
+		want     any
+	}{
+		{
+			name:     "double quotes",
+			inputStr: `window.__DATA__ = JSON.parse("{\"a\":1,\"b\":[true]}");`,
+			want:     map[string]any{"a": 1.0, "b": []any{true}},
+		},
+		{
+			name:     "single quotes",
+			inputStr: `window.__DATA__ = JSON.parse('{"name":"O\'Brien"}');`,
+			want:     map[string]any{"name": "O'Brien"},
+		},
+		{
+			name:     "hex and unicode escapes",
+			inputStr: `JSON.parse('\x7b"city":"Zürich","emoji":"😀","brace":"\u{7d}"\x7d')`,
+			want:     map[string]any{"city": "Zürich", "emoji": "😀", "brace": "}"},
+		},
+		{
+			name:     "line continuation",
+			inputStr: "JSON.parse('{\"a\": \\\n1}')",
+			want:     map[string]any{"a": 1.0},
+		},
+		{
+			name:     "template literal",
+			inputStr: "JSON . parse(`[1, 2]`)",
+			want:     []any{1.0, 2.0},
+		},
+		{
+			name:     "not a literal",
+			inputStr: `JSON.parse(data) || {"fallback": true}`,
+			want:     map[string]any{"fallback": true},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(&tt.inputStr, WithJSONParse())
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseAllJSONParse(t *testing.T) {
+	inputStr := `var a = [0]; var b = JSON.parse('{"b": 1}'); var c = JSON.parse("[\"c\"]");`
+	got, err := collect(ParseAll(context.Background(), &inputStr, WithJSONParse()))
+	if err != nil {
+		t.Fatalf("ParseAll() error = %v", err)
+	}
+	if want := []any{[]any{0.0}, map[string]any{"b": 1.0}, []any{"c"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseAll() = %v, want %v", got, want)
+	}
+
+	value, err := ExtractAssignment(&inputStr, "c", WithJSONParse())
+	if err != nil || !reflect.DeepEqual(value, []any{"c"}) {
+		t.Errorf("ExtractAssignment() = %v, %v, want [c]", value, err)
+	}
+
+	_, err = collect(ParseReader(context.Background(), strings.NewReader(inputStr), WithJSONParse()))
+	if !errors.Is(err, ErrJSONParseReader) {
+		t.Errorf("ParseReader() error = %v, want %v", err, ErrJSONParseReader)
+	}
+}
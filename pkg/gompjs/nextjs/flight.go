@@ -0,0 +1,259 @@
+// Package nextjs decodes the React Server Components payload, known as flight data, which Next.js App Router pages
+// embed as `self.__next_f.push([1, "..."])` calls.
+package nextjs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/proway2/gompjs/internal/memo"
+	"github.com/proway2/gompjs/pkg/gompjs"
+)
+
+var (
+	// ErrNoFlightData is returned by Parse if the page doesn't push any flight data chunk.
+	ErrNoFlightData = errors.New("no flight data found")
+	// ErrUnknownRow is returned by Flight.Resolve for a row id which isn't in the payload.
+	ErrUnknownRow = errors.New("unknown flight row")
+)
+
+// pushCallee is the function the chunks of flight data are pushed with.
+const pushCallee = "self.__next_f.push"
+
+// dataChunk is the type of the chunks holding the payload, other chunks bootstrap the page or hold binary data.
+const dataChunk = 1
+
+// Row is a row of the payload, `id:payload`.
+type Row struct {
+	// ID is the hexadecimal id of the row.
+	ID string
+	// Tag tells the kind of the row, such as "I" for a module import or "HL" for a preload hint,
+	// it's empty for a model row, which is plain JSON, and "T" for a text row.
+	Tag string
+	// Raw is the payload following the tag.
+	Raw string
+	// Value is the payload decoded: JSON is loaded with encoding/json and the text of a text row is a string.
+	Value any
+	// Err is the error decoding the payload, Value is nil then.
+	Err error
+}
+
+// Flight is a decoded flight data payload.
+type Flight struct {
+	// Rows are the rows of the payload, in the order they are found.
+	Rows []Row
+	// index maps the ids to the rows, the last row wins.
+	index map[string]int
+}
+
+// Parse collects the flight data chunks pushed by a Next.js page with `self.__next_f.push([1, "..."])`,
+// joins them and decodes the payload.
+func Parse(inputStr *string) (*Flight, error) {
+	calls, err := gompjs.ExtractCalls(inputStr, []string{pushCallee})
+	if err != nil {
+		return nil, err
+	}
+	var payload strings.Builder
+	found := false
+	for _, call := range calls {
+		if len(call.Args) != 1 {
+			continue
+		}
+		chunk, ok := call.Args[0].([]any)
+		if !ok || len(chunk) < 2 || chunk[0] != float64(dataChunk) {
+			continue
+		}
+		if data, ok := chunk[1].(string); ok {
+			payload.WriteString(data)
+			found = true
+		}
+	}
+	if !found {
+		return nil, ErrNoFlightData
+	}
+	return ParsePayload(payload.String()), nil
+}
+
+// ParsePayload decodes a flight data payload, such as the body of a `text/x-component` response.
+// Rows which can't be decoded are kept with their Err set.
+func ParsePayload(payload string) *Flight {
+	f := &Flight{index: map[string]int{}}
+	for i := 0; i < len(payload); {
+		colon := strings.IndexByte(payload[i:], ':')
+		newline := strings.IndexByte(payload[i:], '\n')
+		if colon < 0 {
+			break
+		}
+		// a line without an id is skipped
+		if newline >= 0 && newline < colon {
+			i += newline + 1
+			continue
+		}
+		row := Row{ID: payload[i : i+colon]}
+		i += colon + 1
+		tagEnd := i
+		for tagEnd < len(payload) && payload[tagEnd] >= 'A' && payload[tagEnd] <= 'Z' {
+			tagEnd++
+		}
+		row.Tag = payload[i:tagEnd]
+		i = tagEnd
+		if row.Tag == "T" {
+			i = readText(payload, i, &row)
+		} else {
+			end := strings.IndexByte(payload[i:], '\n')
+			if end < 0 {
+				end = len(payload) - i
+			}
+			row.Raw = payload[i : i+end]
+			i += end + 1
+			if row.Err = json.Unmarshal([]byte(row.Raw), &row.Value); row.Err != nil {
+				row.Value = nil
+			}
+		}
+		f.index[row.ID] = len(f.Rows)
+		f.Rows = append(f.Rows, row)
+	}
+	return f
+}
+
+// readText reads the text of a text row, `T<hexadecimal length>,<text>`, whose length starts at position i of payload.
+// It returns the position right after the text.
+func readText(payload string, i int, row *Row) int {
+	comma := strings.IndexByte(payload[i:], ',')
+	if comma < 0 {
+		row.Raw, row.Err = payload[i:], errors.New("text row without length")
+		return len(payload)
+	}
+	length, err := strconv.ParseUint(payload[i:i+comma], 16, 0)
+	if err != nil {
+		row.Raw, row.Err = payload[i:], fmt.Errorf("text row length: %w", err)
+		return len(payload)
+	}
+	start := i + comma + 1
+	end := start + int(length)
+	if end > len(payload) {
+		end = len(payload)
+	}
+	row.Raw = payload[start:end]
+	row.Value = row.Raw
+	return end
+}
+
+// Row returns the row id.
+func (f *Flight) Row(id string) (Row, bool) {
+	i, ok := f.index[id]
+	if !ok {
+		return Row{}, false
+	}
+	return f.Rows[i], true
+}
+
+// Resolve returns the value of the row id with its references to other rows resolved.
+//
+// A string starting with "$" followed by a hexadecimal id, optionally followed by a path such as "$2a:props:children",
+// is replaced with the value it refers to, and so are the references to lazy components ("$L"), promises ("$@")
+// and other referenced rows ("$Q", "$W", "$K", "$F", "$h", "$B"). "$$" escapes a dollar sign and "$undefined" is nil.
+// Any other marker, such as a symbol "$Sreact.suspense", is kept, as well as references to rows which are missing
+// or which refer to themselves, so cyclic payloads are resolved too.
+// A row referred to by many rows is resolved once, and its maps and slices are shared by them,
+// but for a row holding a reference back to a row it's nested in, which is resolved at each reference.
+func (f *Flight) Resolve(id string) (any, error) {
+	row, ok := f.Row(id)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownRow, id)
+	}
+	if row.Err != nil {
+		return nil, row.Err
+	}
+	r := resolver{flight: f, rows: memo.New[string, any](id)}
+	return r.value(row.Value), nil
+}
+
+// resolver resolves the references of the rows of a flight.
+type resolver struct {
+	flight *Flight
+	// rows holds the rows being resolved and the ones resolved, which the references with a path index into.
+	rows *memo.Memo[string, any]
+}
+
+// value resolves the references of value.
+func (r *resolver) value(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		resolved := make(map[string]any, len(v))
+		for key, element := range v {
+			resolved[key] = r.value(element)
+		}
+		return resolved
+	case []any:
+		resolved := make([]any, len(v))
+		for i, element := range v {
+			resolved[i] = r.value(element)
+		}
+		return resolved
+	case string:
+		return r.string(v)
+	}
+	return value
+}
+
+// referencePrefixes are the markers of the references to rows other than plain ones.
+const referencePrefixes = "L@QWKFhB"
+
+func (r *resolver) string(s string) any {
+	if len(s) < 2 || s[0] != '$' {
+		return s
+	}
+	switch {
+	case s[1] == '$':
+		return s[1:]
+	case s == "$undefined":
+		return nil
+	}
+	reference := s[1:]
+	if strings.IndexByte(referencePrefixes, reference[0]) >= 0 {
+		reference = reference[1:]
+	}
+	parts := strings.Split(reference, ":")
+	id := parts[0]
+	if _, err := strconv.ParseUint(id, 16, 64); err != nil {
+		return s
+	}
+	value, ok := r.row(id)
+	if !ok {
+		return s
+	}
+	for _, key := range parts[1:] {
+		switch v := value.(type) {
+		case map[string]any:
+			value = v[key]
+		case []any:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(v) {
+				return s
+			}
+			value = v[i]
+		default:
+			return s
+		}
+	}
+	return value
+}
+
+// row returns the value of the row id resolved, or false if it's missing, broken or being resolved.
+func (r *resolver) row(id string) (any, bool) {
+	row, ok := r.flight.Row(id)
+	if !ok || row.Err != nil {
+		return nil, false
+	}
+	// a reference to a row it's nested in is kept as its string
+	if r.rows.Resolving(id) {
+		return nil, false
+	}
+	return r.rows.Resolve(id, id, func() any {
+		return r.value(row.Value)
+	}), true
+}
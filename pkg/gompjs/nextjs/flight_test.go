@@ -0,0 +1,100 @@
+package nextjs
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const page = `<html><body>
+<script>(self.__next_f=self.__next_f||[]).push([0]);self.__next_f.push([2,null])</script>
+<script>self.__next_f.push([1,"1:HL[\"/_next/static/css/app.css\",\"style\"]\n2:I[\"(app)/page\",[\"static/chunks/page.js\"],\"Page\"]\n"])</script>
+<script>self.__next_f.push([1,"3:T18,line one\nline \"two\" 💡4:{\"title\":\"Lamp\",\"price\":12.5,\"tags\":[\"$$new\"],\"desc\":\"$3\"}\n5:[\"$\",\"$L2\",null,{\"product\":\"$4\",\"name\":\"$4:title\",\"self\":\"$5\",\"fallback\":\"$Sreact.suspense\",\"missing\":\"$ff\",\"u\":\"$undefined\"}]\n"])</script>
+<script>self.__next_f.push([1,"6:{\"broken\"\n"])</script>
+</body></html>`
+
+func TestParse(t *testing.T) {
+	input := page
+	flight, err := Parse(&input)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	var ids, tags []string
+	for _, row := range flight.Rows {
+		ids = append(ids, row.ID)
+		tags = append(tags, row.Tag)
+	}
+	if want := []string{"1", "2", "3", "4", "5", "6"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("Parse() row ids = %v, want %v", ids, want)
+	}
+	if want := []string{"HL", "I", "T", "", "", ""}; !reflect.DeepEqual(tags, want) {
+		t.Errorf("Parse() row tags = %q, want %q", tags, want)
+	}
+	if row, ok := flight.Row("3"); !ok || row.Value != "line one\nline \"two\" 💡" {
+		t.Errorf("Row(3) = %+v, want the text", row)
+	}
+	if row, ok := flight.Row("6"); !ok || row.Err == nil || row.Value != nil {
+		t.Errorf("Row(6) = %+v, want an error", row)
+	}
+	if _, ok := flight.Row("7"); ok {
+		t.Error("Row(7) is found")
+	}
+}
+
+func TestResolve(t *testing.T) {
+	input := page
+	flight, err := Parse(&input)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	got, err := flight.Resolve("5")
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	product := map[string]any{"title": "Lamp", "price": 12.5, "tags": []any{"$new"}, "desc": "line one\nline \"two\" 💡"}
+	want := []any{"$", []any{"(app)/page", []any{"static/chunks/page.js"}, "Page"}, nil, map[string]any{
+		"product":  product,
+		"name":     "Lamp",
+		"self":     "$5",
+		"fallback": "$Sreact.suspense",
+		"missing":  "$ff",
+		"u":        nil,
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Resolve() = %#v, want %#v", got, want)
+	}
+	if _, err := flight.Resolve("7"); !errors.Is(err, ErrUnknownRow) {
+		t.Errorf("Resolve() error = %v, want %v", err, ErrUnknownRow)
+	}
+	if _, err := flight.Resolve("6"); err == nil {
+		t.Error("Resolve() error = nil, want the row error")
+	}
+}
+
+func TestResolveShared(t *testing.T) {
+	// every row refers twice to the next one, so the tree has 2^40 leaves once resolved
+	var payload strings.Builder
+	for i := 0; i < 40; i++ {
+		fmt.Fprintf(&payload, "%x:[\"$%x\",\"$%x:0\"]\n", i, i+1, i+1)
+	}
+	fmt.Fprintf(&payload, "%x:[{\"leaf\":true,\"back\":\"$%x\"}]\n", 40, 39)
+	got, err := ParsePayload(payload.String()).Resolve("0")
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	for i := 0; i < 40; i++ {
+		got = got.([]any)[0]
+	}
+	if want := []any{map[string]any{"leaf": true, "back": "$27"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Resolve() leaf = %v, want %v", got, want)
+	}
+}
+
+func TestParseNoFlightData(t *testing.T) {
+	input := "<script>self.__next_f.push([0])</script>"
+	if _, err := Parse(&input); !errors.Is(err, ErrNoFlightData) {
+		t.Errorf("Parse() error = %v, want %v", err, ErrNoFlightData)
+	}
+}
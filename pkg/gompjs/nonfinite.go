@@ -0,0 +1,169 @@
+package gompjs
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// NonFinite tells how NaN, Infinity and -Infinity are converted into JSON, which has no such numbers.
+type NonFinite int
+
+const (
+	// NonFiniteKeep keeps NaN as it is, which only some loaders accept, while Infinity and -Infinity are strings.
+	// It's the default, just as chompjs does.
+	NonFiniteKeep NonFinite = iota
+	// NonFiniteNull converts them into null.
+	NonFiniteNull
+	// NonFiniteString converts them into the strings "NaN", "Infinity" and "-Infinity".
+	NonFiniteString
+	// NonFiniteSentinel converts them into the strings NaNSentinel, InfSentinel and NegInfSentinel,
+	// which ResolveNonFinite or a loader of your own turns back into numbers. So that no string of the input
+	// can be taken for a sentinel, the string values starting with a NUL character get another one,
+	// which ResolveNonFinite removes.
+	NonFiniteSentinel
+	// NonFiniteFail fails loading the objects holding them with *NonFiniteError.
+	// Just as for other loader errors, the streaming functions report it only with WithLoadErrors.
+	NonFiniteFail
+)
+
+// Sentinels NonFiniteSentinel converts the non-finite numbers into, they start with a NUL character
+// while the string values of the input which start with one start with two of them.
+const (
+	NaNSentinel    = "\x00NaN"
+	InfSentinel    = "\x00Infinity"
+	NegInfSentinel = "\x00-Infinity"
+)
+
+// NonFiniteError reports a non-finite number found with NonFiniteFail.
+type NonFiniteError struct {
+	// Value is either NaN, Infinity or -Infinity.
+	Value string
+}
+
+func (e *NonFiniteError) Error() string {
+	return fmt.Sprintf("non-finite number %s", e.Value)
+}
+
+// nonFiniteValues are the non-finite numbers as the lexer emits them, -Infinity comes before Infinity.
+var nonFiniteValues = []string{"NaN", "-Infinity", "Infinity"}
+
+// convert converts the non-finite numbers of the JSON document data, strings are skipped.
+func (p NonFinite) convert(data []byte) ([]byte, error) {
+	if !bytes.Contains(data, []byte("Infinity")) && (p == NonFiniteKeep || !bytes.Contains(data, []byte("NaN"))) &&
+		(p != NonFiniteSentinel || !bytes.Contains(data, []byte(`\`))) {
+		return data, nil
+	}
+	var converted []byte
+	written := 0
+	inString := false
+	for i := 0; i < len(data); i++ {
+		switch c := data[i]; {
+		case c == '"':
+			if !inString && p == NonFiniteSentinel && startsWithNUL(data, i) {
+				converted = append(converted, data[written:i+1]...)
+				converted = append(converted, `\u0000`...)
+				written = i + 1
+			}
+			inString = !inString
+		case c == '\\' && inString:
+			i++
+		case !inString && (c == 'N' || c == 'I' || c == '-'):
+			value := ""
+			for _, v := range nonFiniteValues {
+				if bytes.HasPrefix(data[i:], []byte(v)) {
+					value = v
+					break
+				}
+			}
+			replacement, err := p.replacement(value)
+			if err != nil {
+				return nil, err
+			}
+			if value == "" || replacement == value {
+				continue
+			}
+			converted = append(converted, data[written:i]...)
+			converted = append(converted, replacement...)
+			i += len(value) - 1
+			written = i + 1
+		}
+	}
+	if converted == nil {
+		return data, nil
+	}
+	return append(converted, data[written:]...), nil
+}
+
+// startsWithNUL tells whether the string starting at position i of the JSON document data is a value,
+// rather than a key, whose first character is NUL.
+func startsWithNUL(data []byte, i int) bool {
+	rest := data[i+1:]
+	nul := bytes.HasPrefix(rest, []byte(`\u0000`)) || bytes.HasPrefix(rest, []byte(`\x00`)) ||
+		(bytes.HasPrefix(rest, []byte(`\0`)) && (len(rest) == 2 || !isDigit(rest[2])))
+	if !nul {
+		return false
+	}
+	for j := 0; j < len(rest); j++ {
+		switch rest[j] {
+		case '\\':
+			j++
+		case '"':
+			after := bytes.TrimLeft(rest[j+1:], " \t\n\r")
+			return len(after) == 0 || after[0] != ':'
+		}
+	}
+	return true
+}
+
+// replacement returns the JSON value the non-finite number value is converted into.
+func (p NonFinite) replacement(value string) (string, error) {
+	switch {
+	case value == "":
+		return "", nil
+	case p == NonFiniteNull:
+		return "null", nil
+	case p == NonFiniteString || (p == NonFiniteKeep && value != "NaN"):
+		return `"` + value + `"`, nil
+	case p == NonFiniteSentinel:
+		return `"\u0000` + value + `"`, nil
+	case p == NonFiniteFail:
+		return "", &NonFiniteError{Value: value}
+	}
+	return value, nil
+}
+
+// ResolveNonFinite replaces the sentinels of value, as loaded with NonFiniteSentinel, with math.NaN(), math.Inf(1) and math.Inf(-1),
+// and removes the NUL character added to the strings starting with one.
+// Maps and slices are changed in place.
+func ResolveNonFinite(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, element := range v {
+			v[key] = ResolveNonFinite(element)
+		}
+	case OrderedMap:
+		for i := range v {
+			v[i].Value = ResolveNonFinite(v[i].Value)
+		}
+	case []any:
+		for i, element := range v {
+			v[i] = ResolveNonFinite(element)
+		}
+	case string:
+		switch v {
+		case NaNSentinel:
+			return math.NaN()
+		case InfSentinel:
+			return math.Inf(1)
+		case NegInfSentinel:
+			return math.Inf(-1)
+		}
+		// a string of the input starting with NUL
+		if strings.HasPrefix(v, "\x00") {
+			return v[1:]
+		}
+	}
+	return value
+}
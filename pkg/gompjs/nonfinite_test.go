@@ -0,0 +1,160 @@
+package gompjs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestWithNonFinite(t *testing.T) {
+	input := `{a: NaN, b: Infinity, c: -Infinity, d: [-1, -Infinity], e: "NaN or Infinity", Infinity: 1}`
+	tests := []struct {
+		name    string
+		policy  NonFinite
+		input   string
+		want    any
+		wantErr error
+	}{
+		{
+			name:   "keep",
+			policy: NonFiniteKeep,
+			input:  `{b: Infinity, c: -Infinity, d: [-1, -Infinity]}`,
+			want:   map[string]any{"b": "Infinity", "c": "-Infinity", "d": []any{-1.0, "-Infinity"}},
+		},
+		{
+			name:    "keep NaN",
+			policy:  NonFiniteKeep,
+			input:   input,
+			wantErr: &json.SyntaxError{},
+		},
+		{
+			name:   "null",
+			policy: NonFiniteNull,
+			input:  input,
+			want:   map[string]any{"a": nil, "b": nil, "c": nil, "d": []any{-1.0, nil}, "e": "NaN or Infinity", "Infinity": 1.0},
+		},
+		{
+			name:   "string",
+			policy: NonFiniteString,
+			input:  input,
+			want: map[string]any{
+				"a": "NaN", "b": "Infinity", "c": "-Infinity", "d": []any{-1.0, "-Infinity"}, "e": "NaN or Infinity", "Infinity": 1.0,
+			},
+		},
+		{
+			name:   "sentinel",
+			policy: NonFiniteSentinel,
+			input:  input,
+			want: map[string]any{
+				"a": NaNSentinel, "b": InfSentinel, "c": NegInfSentinel, "d": []any{-1.0, NegInfSentinel}, "e": "NaN or Infinity", "Infinity": 1.0,
+			},
+		},
+		{
+			name:    "fail",
+			policy:  NonFiniteFail,
+			input:   input,
+			wantErr: &NonFiniteError{Value: "NaN"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(&tt.input, WithNonFinite(tt.policy))
+			if tt.wantErr != nil {
+				if err == nil || reflect.TypeOf(err) != reflect.TypeOf(tt.wantErr) {
+					t.Fatalf("Parse() error = %#v, want %T", err, tt.wantErr)
+				}
+				if want, ok := tt.wantErr.(*NonFiniteError); ok && *err.(*NonFiniteError) != *want {
+					t.Errorf("Parse() error = %v, want %v", err, want)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithNonFiniteOrderedKeys(t *testing.T) {
+	input := `{a: NaN, b: Infinity, c: -Infinity, d: [-1, -Infinity], e: "NaN or Infinity", Infinity: 1}`
+	// NaN is kept as it is, which encoding/json doesn't accept
+	infinities := `{b: Infinity, c: -Infinity, d: [-1, -Infinity]}`
+	tests := map[NonFinite]string{
+		NonFiniteKeep:     infinities,
+		NonFiniteNull:     input,
+		NonFiniteString:   input,
+		NonFiniteSentinel: input,
+		NonFiniteFail:     input,
+	}
+	for policy, input := range tests {
+		input := input
+		want, wantErr := Parse(&input, WithNonFinite(policy))
+		got, err := Parse(&input, WithNonFinite(policy), WithOrderedKeys())
+		if !reflect.DeepEqual(err, wantErr) {
+			t.Errorf("Parse() error = %v with WithOrderedKeys and policy %d, want %v", err, policy, wantErr)
+			continue
+		}
+		if err == nil && !reflect.DeepEqual(unordered(got), want) {
+			t.Errorf("Parse() = %v with WithOrderedKeys and policy %d, want %v", got, policy, want)
+		}
+	}
+}
+
+func TestResolveNonFinite(t *testing.T) {
+	got := ResolveNonFinite([]any{NaNSentinel, map[string]any{"inf": InfSentinel, "neg": []any{NegInfSentinel}}, "NaN"}).([]any)
+	if !math.IsNaN(got[0].(float64)) {
+		t.Errorf("ResolveNonFinite() = %v, want NaN", got[0])
+	}
+	want := map[string]any{"inf": math.Inf(1), "neg": []any{math.Inf(-1)}}
+	if !reflect.DeepEqual(got[1], want) || got[2] != "NaN" {
+		t.Errorf("ResolveNonFinite() = %v, want [NaN %v NaN]", got, want)
+	}
+}
+
+func TestNonFiniteSentinelCollision(t *testing.T) {
+	input := `{a: NaN, b: "\u0000NaN", c: ["\u0000Infinity", '\u0000x'], "\u0000NaN": -Infinity}`
+	loaded, err := Parse(&input, WithNonFinite(NonFiniteSentinel))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	m := loaded.(map[string]any)
+	if m["a"] != NaNSentinel || m["b"] == NaNSentinel || m["c"].([]any)[0] == InfSentinel {
+		t.Fatalf("Parse() = %q, want strings of the input apart from the sentinels", m)
+	}
+	got := ResolveNonFinite(m).(map[string]any)
+	if !math.IsNaN(got["a"].(float64)) {
+		t.Errorf(`ResolveNonFinite()["a"] = %v, want NaN`, got["a"])
+	}
+	want := map[string]any{"b": NaNSentinel, "c": []any{InfSentinel, "\x00x"}, NaNSentinel: math.Inf(-1)}
+	delete(got, "a")
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ResolveNonFinite() = %q, want %q", got, want)
+	}
+}
+
+func TestParseJsObjectsNonFiniteFail(t *testing.T) {
+	inputStr := "[1] {a: -Infinity} [2]"
+	got, errs := collectErrors(ParseAll(context.Background(), &inputStr, WithNonFinite(NonFiniteFail)))
+	if want := []any{[]any{1.0}, []any{2.0}}; !reflect.DeepEqual(got, want) || len(errs) != 0 {
+		t.Errorf("ParseJsObjects() = %v, %v, want %v skipping the object without WithLoadErrors", got, errs, want)
+	}
+	got, errs = collectErrors(ParseAll(context.Background(), &inputStr, WithNonFinite(NonFiniteFail), WithLoadErrors()))
+	if want := []any{[]any{1.0}, []any{2.0}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseJsObjects() = %v, want %v", got, want)
+	}
+	var nonFinite *NonFiniteError
+	var decodeErr *DecodeError
+	if len(errs) != 1 || !errors.As(errs[0], &decodeErr) || !errors.As(errs[0], &nonFinite) || nonFinite.Value != "-Infinity" {
+		t.Fatalf("ParseJsObjects() errors = %v, want a *DecodeError of -Infinity", errs)
+	}
+	if decodeErr.Offset != 4 {
+		t.Errorf("DecodeError.Offset = %d, want 4", decodeErr.Offset)
+	}
+}
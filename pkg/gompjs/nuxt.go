@@ -0,0 +1,78 @@
+package gompjs
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/proway2/gompjs/internal/chompjs"
+)
+
+// ErrNotIIFE is returned by ParseNuxt if the state isn't assigned an immediately invoked function returning an object.
+var ErrNotIIFE = errors.New("value is not an immediately invoked function returning an object")
+
+// ParseNuxt parses the state of a Nuxt 2 page, assigned to window.__NUXT__, which is serialized either as an object literal
+// or as an immediately invoked function: `window.__NUXT__=(function(a,b){return {x:a,y:[b]}}(1,"z"))`.
+// The parameters used as values of the object returned, shorthand properties such as `{a}` included,
+// are replaced with the arguments of the call,
+// missing arguments and `void 0` are null, and any other argument which isn't a literal is its source text.
+//
+// Positions of the errors found in the object returned refer to the object with the parameters replaced.
+func ParseNuxt(inputStr *string, opts ...Option) (any, error) {
+	o := newOptions(opts)
+	inputStr, err := lexerInput(o, inputStr)
+	if err != nil {
+		return nil, err
+	}
+	start, ok := chompjs.FindAssignedValue(*inputStr, []string{"window", "__NUXT__"})
+	if !ok {
+		if start, ok = chompjs.FindAssignedValue(*inputStr, []string{"__NUXT__"}); !ok {
+			return nil, ErrNoAssignment
+		}
+	}
+	var parsedString *string
+	// the keys of an object resolved from a function aren't found in the input
+	var keys []int
+	if c := (*inputStr)[start]; c == '{' || c == '[' {
+		if parsedString, err = chompjs.FixStringAt(inputStr, start); err != nil {
+			return nil, newParseError(err)
+		}
+		keys = o.keyOffsets(*inputStr, start)
+	} else {
+		iife, ok := chompjs.ParseIIFE(*inputStr, start)
+		if !ok {
+			return nil, ErrNotIIFE
+		}
+		values := make(map[string]string, len(iife.Params))
+		for i, param := range iife.Params {
+			values[param] = "null"
+			if i < len(iife.Args) {
+				values[param] = argJSON(inputStr, iife.Args[i])
+			}
+		}
+		resolved := chompjs.SubstituteIdentifiers((*inputStr)[iife.Start:iife.End], values)
+		if parsedString, err = chompjs.FixString(&resolved); err != nil {
+			return nil, newParseError(err)
+		}
+	}
+	data, _, err := o.mergeKeys(*parsedString, keys)
+	if err != nil {
+		return nil, err
+	}
+	return o.load(data)
+}
+
+// argJSON returns the JSON text of the argument arg of input.
+func argJSON(inputStr *string, arg chompjs.Arg) string {
+	source := (*inputStr)[arg.Start:arg.End]
+	if source == "void 0" || source == "undefined" {
+		return "null"
+	}
+	if arg.Literal {
+		if parsedString, err := chompjs.FixArgs(inputStr, arg.Before, arg.After); err == nil && len(*parsedString) > 2 {
+			// the argument is lexed as the only element of an array
+			return (*parsedString)[1 : len(*parsedString)-1]
+		}
+	}
+	quoted, _ := json.Marshal(source)
+	return string(quoted)
+}
@@ -0,0 +1,88 @@
+package gompjs
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseNuxt(t *testing.T) {
+	tests := []struct {
+		name     string
+		inputStr string
+		want     any
+	}{
+		{
+			name: "iife",
+			inputStr: `<script>window.__NUXT__=(function(a,b,c,d,e){d.x=1;return {layout:"default",data:[{title:a,tags:[b,"b"],empty:c}],` +
+				`state:{a:{a:a},count:e,url:"/p/1"},fn:function(a){return a}}}("Lamp",true,void 0,{},-2.5));</script>`,
+			want: map[string]any{
+				"layout": "default",
+				"data":   []any{map[string]any{"title": "Lamp", "tags": []any{true, "b"}, "empty": nil}},
+				"state":  map[string]any{"a": map[string]any{"a": "Lamp"}, "count": -2.5, "url": "/p/1"},
+				"fn":     "function(a){return a}",
+			},
+		},
+		{
+			name:     "called after the parenthesis",
+			inputStr: `__NUXT__ = (function (a, b) { return [a, b, 'c'] })(1, window.x);`,
+			want:     []any{1.0, "window.x", "c"},
+		},
+		{
+			name:     "missing arguments",
+			inputStr: `window.__NUXT__=(function(a,b){return {a:a,b:b}}(1))`,
+			want:     map[string]any{"a": 1.0, "b": nil},
+		},
+		{
+			name:     "minified literals",
+			inputStr: `window.__NUXT__=(function(a,b,c,d){return {x:b,y:a,z:c,w:[b,c,d],serverRendered:b,e:{d}}}(1,true,null,false))`,
+			want: map[string]any{
+				"x": true, "y": 1.0, "z": nil, "w": []any{true, nil, false},
+				"serverRendered": true, "e": map[string]any{"d": false},
+			},
+		},
+		{
+			name:     "shorthand properties",
+			inputStr: `window.__NUXT__=(function(a,b,c){return {a, b: [b, {c}], /* key */ c: a, d: {a,c}}}(1,"x",null))`,
+			want: map[string]any{
+				"a": 1.0,
+				"b": []any{"x", map[string]any{"c": nil}},
+				"c": 1.0,
+				"d": map[string]any{"a": 1.0, "c": nil},
+			},
+		},
+		{
+			name:     "object literal",
+			inputStr: `window.__NUXT__ = {state: {a: 1}}`,
+			want:     map[string]any{"state": map[string]any{"a": 1.0}},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseNuxt(&tt.inputStr)
+			if err != nil {
+				t.Fatalf("ParseNuxt() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseNuxt() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseNuxtErrors(t *testing.T) {
+	tests := []struct {
+		inputStr string
+		want     error
+	}{
+		{`var state = {a: 1}`, ErrNoAssignment},
+		{`window.__NUXT__ = getState()`, ErrNotIIFE},
+		{`window.__NUXT__ = (function(a){var x = a})(1)`, ErrNotIIFE},
+	}
+	for _, tt := range tests {
+		if _, err := ParseNuxt(&tt.inputStr); !errors.Is(err, tt.want) {
+			t.Errorf("ParseNuxt(%q) error = %v, want %v", tt.inputStr, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package gompjs
+
+import (
+	"context"
+	"io"
+
+	"github.com/proway2/gompjs/internal/chompjs"
+)
+
+// Span tells where an object is found in the input.
+// Positions refer to the input the lexer gets, i.e. after unicode escape sequences are decoded.
+type Span struct {
+	// Start is the byte offset of the opening bracket of the object.
+	Start int
+	// End is the byte offset right after the closing bracket of the object, so input[Start:End] is the object.
+	End int
+	// Line is the 1-based line number of Start.
+	Line int
+	// Column is the 1-based column of Start, counted in characters.
+	Column int
+}
+
+// Object is an object parsed along with its position in the input.
+type Object struct {
+	Value any
+	Span  Span
+	// Duplicates are the duplicate keys of the object, they are only looked for with WithDuplicateReport
+	// or a DuplicateKeys policy other than DuplicateLast.
+	Duplicates []DuplicateKey
+}
+
+// ParseAllObjects is ParseAll sending every object along with its position in the input.
+func ParseAllObjects(ctx context.Context, inputStr *string, opts ...Option) (<-chan Object, <-chan error) {
+	o := newOptions(opts)
+	return streamObjects(ctx, inputStr, o, loadObject(o))
+}
+
+// ParseReaderObjects is ParseReader sending every object along with its position in the whole input read.
+func ParseReaderObjects(ctx context.Context, r io.Reader, opts ...Option) (<-chan Object, <-chan error) {
+	o := newOptions(opts)
+	return streamReader(ctx, r, o, loadObject(o))
+}
+
+// loadObject loads elements just as loadAny does and adds their positions and duplicate keys.
+func loadObject(o Options) loadFunc[Object] {
+	return func(object *chompjs.Object) (Object, bool, error) {
+		value, duplicates, keep, err := o.loadElement(object)
+		return Object{
+			Value:      value,
+			Span:       Span{Start: object.Start, End: object.End, Line: object.Line, Column: object.Column},
+			Duplicates: duplicates,
+		}, keep, err
+	}
+}
@@ -0,0 +1,74 @@
+package gompjs
+
+import (
+	"context"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestParseAllObjects(t *testing.T) {
+	input := "<script>var a = {'x': 1};</script>\n<script>\n  zażółć = [2, 3]\n</script>"
+	want := []Object{
+		{Value: map[string]any{"x": 1.0}, Span: Span{Start: 16, End: 24, Line: 1, Column: 17}},
+		{Value: []any{2.0, 3.0}, Span: Span{Start: 59, End: 65, Line: 3, Column: 12}},
+	}
+	sources := map[string]func() (<-chan Object, <-chan error){
+		"string": func() (<-chan Object, <-chan error) {
+			return ParseAllObjects(context.Background(), &input)
+		},
+		"one byte reader": func() (<-chan Object, <-chan error) {
+			return ParseReaderObjects(context.Background(), iotest.OneByteReader(strings.NewReader(input)))
+		},
+		"reader": func() (<-chan Object, <-chan error) {
+			return ParseReaderObjects(context.Background(), io.MultiReader(strings.NewReader(input[:40]), strings.NewReader(input[40:])))
+		},
+	}
+	for name, parse := range sources {
+		parse := parse
+		t.Run(name, func(t *testing.T) {
+			dataChannel, errChannel := parse()
+			var got []Object
+			for object := range dataChannel {
+				got = append(got, object)
+			}
+			if err := <-errChannel; err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("objects = %+v, want %+v", got, want)
+			}
+			for i, raw := range []string{"{'x': 1}", "[2, 3]"} {
+				if span := input[got[i].Span.Start:got[i].Span.End]; span != raw {
+					t.Errorf("input[Start:End] = %q, want %q", span, raw)
+				}
+			}
+		})
+	}
+}
+
+func TestObjectSpanInInput(t *testing.T) {
+	// the lexer may move past the end of the input when it fails on its last characters,
+	// a loader accepting anything makes its output an object
+	inputs := []string{
+		"{/*a-Infinity-\n`-Infinity[*/",
+		"{a: 1} [2, {b: 'c'",
+		"var x = {a: `b",
+	}
+	loader := WithLoader(func([]byte, any) error { return nil })
+	for _, input := range inputs {
+		dataChannel, errChannel := ParseAllObjects(context.Background(), &input, loader)
+		for object := range dataChannel {
+			if span := object.Span; span.Start < 0 || span.Start > span.End || span.End > len(input) {
+				t.Errorf("Span = %+v in %q, out of its %d bytes", span, input, len(input))
+			} else if raw := input[span.Start:span.End]; raw == "" || !strings.ContainsAny(raw[:1], "{[") {
+				t.Errorf("input[Start:End] = %q in %q, want an object", raw, input)
+			}
+		}
+		if err := <-errChannel; err != nil {
+			t.Errorf("error = %v for %q", err, input)
+		}
+	}
+}
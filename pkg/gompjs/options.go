@@ -0,0 +1,182 @@
+package gompjs
+
+import (
+	"encoding/json"
+
+	"github.com/proway2/gompjs/internal/chompjs"
+)
+
+// Options holds the parsing settings. The defaults parse the input just as Python's chompjs does,
+// loading objects with encoding/json.
+type Options struct {
+	// UnicodeEscape decodes escape sequences of the input before parsing it.
+	UnicodeEscape bool
+	// JSONParse parses the documents passed to JSON.parse as string literals.
+	JSONParse bool
+	// OmitEmpty skips empty objects and lists when parsing many objects.
+	OmitEmpty bool
+	// Loader loads the objects converted into JSON.
+	Loader UnmarshalFunc
+	// ParseErrors reports malformed objects as *ParseError when parsing many objects.
+	ParseErrors bool
+	// LoadErrors reports objects the loader fails on as *DecodeError when parsing many objects.
+	LoadErrors bool
+	// Callback is the callback ParseJSONP expects, any callback if it's empty.
+	Callback string
+	// ScriptTypes are the types of the scripts ParseHTML parses, all of them if it's empty.
+	ScriptTypes []string
+	// NonFinite tells how NaN, Infinity and -Infinity are converted into JSON.
+	NonFinite NonFinite
+	// BigIntStrings converts the integers float64 can't hold exactly into strings.
+	BigIntStrings bool
+	// OrderedKeys loads objects as OrderedMap with the built-in decoder instead of the loader.
+	OrderedKeys bool
+	// DuplicateKeys tells how the members of an object sharing a key are loaded.
+	DuplicateKeys DuplicateKeys
+	// DuplicateReport is called with every duplicate key found, whatever the DuplicateKeys policy.
+	DuplicateReport func(DuplicateKey)
+}
+
+// Option changes one of the Options.
+type Option func(*Options)
+
+// WithUnicodeEscape decodes escape sequences of the input before parsing it, see strconv.Unquote.
+func WithUnicodeEscape() Option {
+	return func(o *Options) {
+		o.UnicodeEscape = true
+	}
+}
+
+// WithJSONParse parses the document passed to JSON.parse as a string literal, such as `JSON.parse("{\"a\": 1}")`,
+// instead of taking it for a string. The literal is unescaped just as JavaScript does,
+// positions then refer to the input with the calls replaced by their documents.
+func WithJSONParse() Option {
+	return func(o *Options) {
+		o.JSONParse = true
+	}
+}
+
+// WithOmitEmpty skips empty objects and lists when parsing many objects.
+func WithOmitEmpty() Option {
+	return func(o *Options) {
+		o.OmitEmpty = true
+	}
+}
+
+// WithParseErrors reports every malformed object as a *ParseError when parsing many objects,
+// and carries on parsing right after it. By default whatever is parsed before the error is loaded, just as chompjs does.
+func WithParseErrors() Option {
+	return func(o *Options) {
+		o.ParseErrors = true
+	}
+}
+
+// WithLoadErrors reports every object the loader fails on as a *DecodeError when parsing many objects,
+// holding its JSON text and the loader error. By default these objects are skipped, just as chompjs does.
+func WithLoadErrors() Option {
+	return func(o *Options) {
+		o.LoadErrors = true
+	}
+}
+
+// WithScriptTypes makes ParseHTML parse only the scripts of the given types, such as "application/json".
+// Types are compared case-insensitively without parameters, and a script without type is "text/javascript".
+func WithScriptTypes(types ...string) Option {
+	return func(o *Options) {
+		o.ScriptTypes = append(o.ScriptTypes, types...)
+	}
+}
+
+// WithCallback makes ParseJSONP check that the callback called is name, such as "jQuery112_1699".
+func WithCallback(name string) Option {
+	return func(o *Options) {
+		o.Callback = name
+	}
+}
+
+// WithNonFinite converts NaN, Infinity and -Infinity into JSON following policy, see NonFinite.
+func WithNonFinite(policy NonFinite) Option {
+	return func(o *Options) {
+		o.NonFinite = policy
+	}
+}
+
+// WithBigIntStrings converts the integers float64 can't hold exactly, the ones past 2^53-1 such as 64-bit ids,
+// into strings holding all their digits, e.g. {"id": "18446744073709551615"}.
+func WithBigIntStrings() Option {
+	return func(o *Options) {
+		o.BigIntStrings = true
+	}
+}
+
+// WithOrderedKeys loads objects as OrderedMap, which keeps the order of their keys, instead of map[string]any.
+// Objects are decoded with the built-in decoder of ParseValue rather than the loader, numbers are float64
+// just as encoding/json loads them.
+func WithOrderedKeys() Option {
+	return func(o *Options) {
+		o.OrderedKeys = true
+	}
+}
+
+// WithDuplicateKeys tells how the members of an object sharing a key are loaded, see DuplicateKeys.
+func WithDuplicateKeys(policy DuplicateKeys) Option {
+	return func(o *Options) {
+		o.DuplicateKeys = policy
+	}
+}
+
+// WithDuplicateReport calls report with every duplicate key found, along with its byte offset in the input,
+// whatever the DuplicateKeys policy, the default one included. Keys are reported in the order of the input,
+// the streaming functions call report from their goroutine before sending the object.
+func WithDuplicateReport(report func(DuplicateKey)) Option {
+	return func(o *Options) {
+		o.DuplicateReport = report
+	}
+}
+
+// WithLoader loads the objects with loader instead of encoding/json's Unmarshal.
+func WithLoader(loader UnmarshalFunc) Option {
+	return func(o *Options) {
+		if loader != nil {
+			o.Loader = loader
+		}
+	}
+}
+
+// newOptions applies opts to the default options.
+func newOptions(opts []Option) Options {
+	o := Options{Loader: json.Unmarshal}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	o.Loader = o.loader(o.Loader)
+	return o
+}
+
+// loader returns loader loading the JSON documents converted following the options.
+func (o Options) loader(loader UnmarshalFunc) UnmarshalFunc {
+	return func(data []byte, v any) error {
+		data, err := o.NonFinite.convert(data)
+		if err != nil {
+			return err
+		}
+		if o.BigIntStrings {
+			data = quoteBigInts(data)
+		}
+		return loader(data, v)
+	}
+}
+
+// lexerOptions returns the options of the lexer.
+func (o Options) lexerOptions() chompjs.Options {
+	return chompjs.Options{ReportErrors: o.ParseErrors, KeyOffsets: o.findsDuplicates()}
+}
+
+// positional sets the options passed as positional arguments to the compatibility functions.
+func positional(unicodeEscape, omitEmpty bool, loader UnmarshalFunc) Option {
+	return func(o *Options) {
+		o.UnicodeEscape = unicodeEscape
+		o.OmitEmpty = omitEmpty
+		WithLoader(loader)(o)
+	}
+}
@@ -0,0 +1,134 @@
+package gompjs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseOptions(t *testing.T) {
+	for _, ut := range []tests{objectTests, mixedTests, strangeValues, unicodeEscapeTests} {
+		for _, tt := range ut {
+			inputStr := tt.args.inputStr
+			var opts []Option
+			if tt.args.unicodeEscape {
+				opts = append(opts, WithUnicodeEscape())
+			}
+			got, err := Parse(&inputStr, opts...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse(%q) error = %v, wantErr %v", inputStr, err, tt.wantErr)
+				continue
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse(%q) = %v, want %v", inputStr, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestParseAllOptions(t *testing.T) {
+	inputStr := "[1][][2]{}"
+	calls := 0
+	loader := func(data []byte, v any) error {
+		calls++
+		return json.Unmarshal(data, v)
+	}
+	got, err := collect(ParseAll(context.Background(), &inputStr, WithOmitEmpty(), WithLoader(loader)))
+	if err != nil {
+		t.Fatalf("ParseAll() error = %v", err)
+	}
+	if want := []any{[]any{float64(1)}, []any{float64(2)}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseAll() = %v, want %v", got, want)
+	}
+	if calls != 4 {
+		t.Errorf("ParseAll() called the loader %d times, want 4", calls)
+	}
+
+	inputStr = "[\\\"a\\\"] {\\\"b\\\": 1}"
+	got, err = collect(ParseAll(context.Background(), &inputStr, WithUnicodeEscape()))
+	if err != nil {
+		t.Fatalf("ParseAll() error = %v", err)
+	}
+	if want := []any{[]any{"a"}, map[string]any{"b": float64(1)}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseAll() = %v, want %v", got, want)
+	}
+
+	inputStr = "\\q"
+	if _, err = collect(ParseAll(context.Background(), &inputStr, WithUnicodeEscape())); err == nil {
+		t.Error("ParseAll() error = nil, want an unicode escape error")
+	}
+}
+
+func TestParseReaderOptions(t *testing.T) {
+	_, err := collect(ParseReader(context.Background(), strings.NewReader("[1]"), WithUnicodeEscape()))
+	if !errors.Is(err, ErrUnicodeEscapeReader) {
+		t.Errorf("ParseReader() error = %v, want %v", err, ErrUnicodeEscapeReader)
+	}
+	got, err := collect(ParseReader(context.Background(), strings.NewReader("[1] [] {a: 2}"), WithOmitEmpty()))
+	if err != nil {
+		t.Fatalf("ParseReader() error = %v", err)
+	}
+	if want := []any{[]any{float64(1)}, map[string]any{"a": float64(2)}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseReader() = %v, want %v", got, want)
+	}
+}
+
+func TestParseAsOptions(t *testing.T) {
+	inputStr := "{name: 'Lamp', price: 12.5} {name: 'Desk', price: 40}"
+	got, err := ParseAs[product](&inputStr)
+	if err != nil {
+		t.Fatalf("ParseAs() error = %v", err)
+	}
+	if want := (product{Name: "Lamp", Price: 12.5}); !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseAs() = %+v, want %+v", got, want)
+	}
+	dataChannel, errChannel := ParseAllAs[product](context.Background(), &inputStr)
+	var products []product
+	for p := range dataChannel {
+		products = append(products, p)
+	}
+	if err := <-errChannel; err != nil {
+		t.Fatalf("ParseAllAs() error = %v", err)
+	}
+	if len(products) != 2 || products[1].Name != "Desk" {
+		t.Errorf("ParseAllAs() = %+v", products)
+	}
+}
+
+func TestParseAllLoadErrors(t *testing.T) {
+	inputStr := "[1] {a: NaN} [2]"
+	wantData := []any{[]any{float64(1)}, []any{float64(2)}}
+	for name, opts := range map[string][]Option{"default": nil, "load errors": {WithLoadErrors()}} {
+		got, errs := collectErrors(ParseAll(context.Background(), &inputStr, opts...))
+		if !reflect.DeepEqual(got, wantData) {
+			t.Errorf("%s: ParseAll() = %v, want %v", name, got, wantData)
+		}
+		if opts == nil {
+			if len(errs) != 0 {
+				t.Errorf("%s: ParseAll() errors = %v, want none", name, errs)
+			}
+			continue
+		}
+		var decodeErr *DecodeError
+		if len(errs) != 1 || !errors.As(errs[0], &decodeErr) {
+			t.Fatalf("%s: ParseAll() errors = %v, want a *DecodeError", name, errs)
+		}
+		if decodeErr.Raw != `{"a":NaN}` || decodeErr.Offset != 4 || decodeErr.Err == nil {
+			t.Errorf("%s: DecodeError = %q at %d, want {\"a\":NaN} at 4", name, decodeErr.Raw, decodeErr.Offset)
+		}
+	}
+
+	got, errs := collectErrors(ParseReader(context.Background(), strings.NewReader(inputStr), WithLoadErrors()))
+	var decodeErr *DecodeError
+	if !reflect.DeepEqual(got, wantData) || len(errs) != 1 || !errors.As(errs[0], &decodeErr) || decodeErr.Offset != 4 {
+		t.Errorf("ParseReader() = %v, %v, want %v and a *DecodeError at 4", got, errs, wantData)
+	}
+
+	got, errs = collectErrors(ParseAllAs[any](context.Background(), &inputStr, WithLoadErrors()))
+	if !reflect.DeepEqual(got, wantData) || len(errs) != 1 || !errors.As(errs[0], &decodeErr) || decodeErr.Offset != 4 {
+		t.Errorf("ParseAllAs() = %v, %v, want %v and a *DecodeError at 4", got, errs, wantData)
+	}
+}
@@ -0,0 +1,89 @@
+package gompjs
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// KeyValue is a member of an OrderedMap.
+type KeyValue struct {
+	Key   string
+	Value any
+}
+
+// OrderedMap is an object loaded with WithOrderedKeys, its members are in the order of the input.
+type OrderedMap []KeyValue
+
+// Get returns the value of the last member whose key is key.
+func (m OrderedMap) Get(key string) (any, bool) {
+	for i := len(m) - 1; i >= 0; i-- {
+		if m[i].Key == key {
+			return m[i].Value, true
+		}
+	}
+	return nil, false
+}
+
+// Keys returns the keys of the members in order.
+func (m OrderedMap) Keys() []string {
+	keys := make([]string, len(m))
+	for i, kv := range m {
+		keys[i] = kv.Key
+	}
+	return keys
+}
+
+// MarshalJSON returns the JSON text of the object with its members in order.
+func (m OrderedMap) MarshalJSON() ([]byte, error) {
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, kv := range m {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		key, err := json.Marshal(kv.Key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(kv.Value)
+		if err != nil {
+			return nil, err
+		}
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(value)
+	}
+	b.WriteByte('}')
+	return []byte(b.String()), nil
+}
+
+// ordered returns v the way Any does, but with objects loaded as OrderedMap following duplicates.
+func (v *Value) ordered(duplicates DuplicateKeys) any {
+	switch v.Kind() {
+	case KindArray:
+		items := make([]any, len(v.items))
+		for i := range v.items {
+			items[i] = v.items[i].ordered(duplicates)
+		}
+		return items
+	case KindObject:
+		members := make(OrderedMap, 0, len(v.members))
+		var index map[string]int
+		for i := range v.members {
+			member := KeyValue{Key: v.members[i].Key, Value: v.members[i].Value.ordered(duplicates)}
+			if duplicates == DuplicateLast {
+				if index == nil {
+					index = make(map[string]int, len(v.members))
+				}
+				if j, ok := index[member.Key]; ok {
+					members[j].Value = member.Value
+					continue
+				}
+				index[member.Key] = len(members)
+			}
+			members = append(members, member)
+		}
+		return members
+	}
+	return v.Any()
+}
@@ -0,0 +1,75 @@
+package gompjs
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestWithOrderedKeys(t *testing.T) {
+	input := `{specs: {weight: '2 kg', color: 'red', size: 'L', color: 'blue'}, list: [{z: 1, a: 2}]}`
+	tests := []struct {
+		name       string
+		duplicates DuplicateKeys
+		want       any
+	}{
+		{
+			name:       "last value",
+			duplicates: DuplicateLast,
+			want: OrderedMap{
+				{"specs", OrderedMap{{"weight", "2 kg"}, {"color", "blue"}, {"size", "L"}}},
+				{"list", []any{OrderedMap{{"z", 1.0}, {"a", 2.0}}}},
+			},
+		},
+		{
+			name:       "every value",
+			duplicates: DuplicateKeepAll,
+			want: OrderedMap{
+				{"specs", OrderedMap{{"weight", "2 kg"}, {"color", "red"}, {"size", "L"}, {"color", "blue"}}},
+				{"list", []any{OrderedMap{{"z", 1.0}, {"a", 2.0}}}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(&input, WithOrderedKeys(), WithDuplicateKeys(tt.duplicates))
+			if err != nil {
+				t.Fatalf("ParseJsObject() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseJsObject() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrderedMap(t *testing.T) {
+	m := OrderedMap{{"b", 1.0}, {"a", []any{OrderedMap{{"y", nil}, {"x", true}}}}, {"b", 2.0}}
+	if got, ok := m.Get("b"); !ok || got != 2.0 {
+		t.Errorf(`Get("b") = %v, %v, want the last value 2`, got, ok)
+	}
+	if _, ok := m.Get("c"); ok {
+		t.Error(`Get("c") is found`)
+	}
+	if got, want := m.Keys(), []string{"b", "a", "b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Keys() = %v, want %v", got, want)
+	}
+	marshaled, err := json.Marshal(m)
+	if want := `{"b":1,"a":[{"y":null,"x":true}],"b":2}`; err != nil || string(marshaled) != want {
+		t.Errorf("json.Marshal() = %s, %v, want %s", marshaled, err, want)
+	}
+}
+
+func TestParseJsObjectsOrderedKeys(t *testing.T) {
+	input := "{} {b: 1, a: 2} [{d: 3, c: 4}]"
+	got, errs := collectErrors(ParseAll(context.Background(), &input, WithOmitEmpty(), WithOrderedKeys()))
+	if len(errs) != 0 {
+		t.Fatalf("ParseJsObjects() errors = %v", errs)
+	}
+	want := []any{OrderedMap{{"b", 1.0}, {"a", 2.0}}, []any{OrderedMap{{"d", 3.0}, {"c", 4.0}}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseJsObjects() = %v, want %v", got, want)
+	}
+}
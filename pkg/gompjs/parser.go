@@ -1,6 +1,8 @@
 package gompjs
 
 import (
+	"context"
+	"errors"
 	"strconv"
 
 	"github.com/proway2/gompjs/internal/chompjs"
@@ -8,77 +10,267 @@ import (
 
 type UnmarshalFunc func([]byte, any) error
 
+// ParseJsObject is Parse with positional arguments, equivalent to chompjs.parse_js_object.
 func ParseJsObject(inputStr *string, unicodeEscape bool, loader UnmarshalFunc) (any, error) {
-	var err error
-	if unicodeEscape {
-		if inputStr, err = decodeUnicodeEscape(inputStr); err != nil {
-			return nil, err
-		}
+	return Parse(inputStr, positional(unicodeEscape, false, loader))
+}
+
+// ParseJsObjects is ParseAll with positional arguments, equivalent to chompjs.parse_js_objects.
+func ParseJsObjects(inputStr *string, unicodeEscape, omitEmpty bool, loader UnmarshalFunc) (<-chan any, <-chan error) {
+	return ParseAll(context.Background(), inputStr, positional(unicodeEscape, omitEmpty, loader))
+}
+
+// ParseJsObjectsContext is ParseAll with positional arguments.
+func ParseJsObjectsContext(ctx context.Context, inputStr *string, unicodeEscape, omitEmpty bool, loader UnmarshalFunc) (<-chan any, <-chan error) {
+	return ParseAll(ctx, inputStr, positional(unicodeEscape, omitEmpty, loader))
+}
+
+// Parse converts the first JavaScript object found in the input into JSON and loads it.
+// An input the lexer can't parse is reported as *ParseError.
+func Parse(inputStr *string, opts ...Option) (any, error) {
+	o := newOptions(opts)
+	inputStr, err := lexerInput(o, inputStr)
+	if err != nil {
+		return nil, err
 	}
 	var parsedString *string
 	if parsedString, err = chompjs.FixString(inputStr); err != nil {
-		return nil, err
+		return nil, newParseError(err)
 	}
-	var res any
-	byteParsedString := []byte(*parsedString)
-	if err = parseString(loader, &byteParsedString, &res); err != nil {
+	data, _, err := o.mergeKeys(*parsedString, o.keyOffsets(*inputStr, 0))
+	if err != nil {
 		return nil, err
 	}
-	return res, nil
+	return o.load(data)
 }
 
-func ParseJsObjects(inputStr *string, unicodeEscape, omitEmpty bool, loader UnmarshalFunc) (<-chan any, <-chan error) {
-	dataChannel := make(chan any)
-	errChannel := make(chan error, 1)
-	var err error
-	if unicodeEscape {
-		if inputStr, err = decodeUnicodeEscape(inputStr); err != nil {
-			errChannel <- err
-			return dataChannel, errChannel
+// ParseAll converts every JavaScript object found in the input into JSON, loads them
+// and sends them one by one into the data channel. Objects the loader can't load are skipped.
+//
+// Once ctx is done parsing stops, all the memory held by the lexer is released,
+// ctx.Err() is sent into the error channel and both channels are closed.
+// Errors are queued while objects are sent, the error channel is closed once they are all read,
+// after the data channel.
+func ParseAll(ctx context.Context, inputStr *string, opts ...Option) (<-chan any, <-chan error) {
+	o := newOptions(opts)
+	return streamObjects(ctx, inputStr, o, loadAny(o))
+}
+
+// loadFunc loads an object coming from chompjs. Unless keep is set the element is skipped,
+// a non-nil error is reported without ending the stream.
+type loadFunc[T any] func(object *chompjs.Object) (element T, keep bool, err error)
+
+// loadAny loads elements the way Python's chompjs does.
+func loadAny(o Options) loadFunc[any] {
+	return func(object *chompjs.Object) (any, bool, error) {
+		element, _, keep, err := o.loadElement(object)
+		return element, keep, err
+	}
+}
+
+// loadElement is loadAny returning the duplicate keys of the object as well.
+func (o Options) loadElement(object *chompjs.Object) (any, []DuplicateKey, bool, error) {
+	data, duplicates, err := o.mergeKeys(object.JSON, object.Keys)
+	if err == nil {
+		var element any
+		if element, err = o.load(data); err == nil {
+			return element, duplicates, !o.omits(element), nil
 		}
 	}
+	if o.LoadErrors {
+		return nil, nil, false, &DecodeError{Raw: object.JSON, Offset: object.Start, Err: err}
+	}
+	// Original Python code skips on loader error
+	// try:
+	// 	data = loader(raw_data, *loader_args, **loader_kwargs)
+	// except ValueError:
+	// 	continue
+	return nil, nil, false, nil
+}
+
+// omits tells whether element is skipped, being an empty object or list with OmitEmpty.
+func (o Options) omits(element any) bool {
+	if !o.OmitEmpty {
+		return false
+	}
+	switch v := element.(type) {
+	case []any:
+		return len(v) == 0
+	case map[string]any:
+		return len(v) == 0
+	case OrderedMap:
+		return len(v) == 0
+	}
+	return false
+}
+
+// streamObjects runs chompjs over the input and loads every object it finds with load.
+func streamObjects[T any](ctx context.Context, inputStr *string, o Options, load loadFunc[T]) (<-chan T, <-chan error) {
+	return streamParts(ctx, inputStr, o, nil, load)
+}
+
+// streamParts is streamObjects lexing only the parts of the input split returns, unless split is nil.
+// split is called with the input the lexer gets before load is called.
+func streamParts[T any](ctx context.Context, inputStr *string, o Options, split func(input string) []chompjs.Part, load loadFunc[T]) (<-chan T, <-chan error) {
+	dataChannel := make(chan T)
+	errChannel := make(chan error, 1)
+	inputStr, err := lexerInput(o, inputStr)
+	if err != nil {
+		errChannel <- err
+		close(errChannel)
+		close(dataChannel)
+		return dataChannel, errChannel
+	}
 	go func() {
-		defer close(dataChannel)
+		// loadObjects closes the data channel
 		defer close(errChannel)
-		chompjsResCh, chompjsErrCh := chompjs.FixStrings(inputStr)
-		for {
-			select {
-			case parsedString, ok := <-chompjsResCh:
-				if !ok {
-					return
-				}
-				var element any
-				byteParsedString := []byte(*parsedString)
-				if err := parseString(loader, &byteParsedString, &element); err != nil {
-					// Original Python code skips on loader error
-					// try:
-					// 	data = loader(raw_data, *loader_args, **loader_kwargs)
-					// except ValueError:
-					// 	continue
-					continue
-				}
-				if omitEmpty {
-					switch v := element.(type) {
-					case []any:
-						if len(v) == 0 {
-							continue
-						}
-					case map[string]any:
-						if len(v) == 0 {
-							continue
-						}
+		// stops the lexer whenever this goroutine returns
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		var chompjsResCh <-chan *chompjs.Object
+		var chompjsErrCh <-chan error
+		if split == nil {
+			chompjsResCh, chompjsErrCh = chompjs.FixStringsContext(ctx, inputStr, o.lexerOptions())
+		} else {
+			chompjsResCh, chompjsErrCh = chompjs.FixPartsContext(ctx, inputStr, split(*inputStr), o.lexerOptions())
+		}
+		loadObjects(ctx, chompjsResCh, chompjsErrCh, load, dataChannel, errChannel)
+	}()
+	return dataChannel, errChannel
+}
+
+// loadObjects loads every string coming from chompjs and sends the results into dataChannel,
+// an error coming from chompjs ends it, unless it's a malformed object. It closes dataChannel once it's done,
+// errors are queued meanwhile, so a consumer reading dataChannel until it's closed before errChannel doesn't block it.
+func loadObjects[T any](ctx context.Context, chompjsResCh <-chan *chompjs.Object, chompjsErrCh <-chan error, load loadFunc[T], dataChannel chan<- T, errChannel chan<- error) {
+	queue := &errorQueue{errChannel: errChannel}
+	defer func() {
+		close(dataChannel)
+		queue.flush(ctx)
+	}()
+	for {
+		select {
+		case object, ok := <-chompjsResCh:
+			if !ok {
+				// the error channel is closed first, so a pending error is already there
+				if chompjsErrCh != nil {
+					if err, ok := <-chompjsErrCh; ok {
+						queue.add(newParseError(err))
 					}
 				}
-				dataChannel <- element
-			case err := <-chompjsErrCh:
-				if err != nil {
-					errChannel <- err
-					return
-				}
+				return
+			}
+			element, keep, err := load(object)
+			if err != nil {
+				queue.add(err)
 			}
+			if keep && !sendElement(ctx, dataChannel, element, queue) {
+				return
+			}
+		case err, ok := <-chompjsErrCh:
+			if !ok {
+				// the data channel is being closed right after this one
+				chompjsErrCh = nil
+				continue
+			}
+			queue.add(newParseError(err))
+			// malformed objects come only with WithParseErrors and don't end the stream
+			var lexerErr *chompjs.Error
+			if !errors.As(err, &lexerErr) {
+				return
+			}
+		case queue.out() <- queue.head():
+			queue.pop()
 		}
-	}()
-	return dataChannel, errChannel
+	}
+}
+
+// sendElement sends element into dataChannel, along with the errors of queue, unless ctx is done first,
+// in which case it returns false.
+func sendElement[T any](ctx context.Context, dataChannel chan<- T, element T, queue *errorQueue) bool {
+	for {
+		select {
+		case dataChannel <- element:
+			return true
+		case queue.out() <- queue.head():
+			queue.pop()
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+// errorQueue holds the errors of a stream which are yet to be sent into errChannel,
+// so that sending the objects never waits for the consumer to read the errors.
+type errorQueue struct {
+	errChannel chan<- error
+	pending    []error
+}
+
+func (q *errorQueue) add(err error) {
+	q.pending = append(q.pending, err)
+}
+
+// out returns errChannel if there's an error pending and nil otherwise, which disables its case in a select.
+func (q *errorQueue) out() chan<- error {
+	if len(q.pending) == 0 {
+		return nil
+	}
+	return q.errChannel
+}
+
+// head returns the first error pending, if any.
+func (q *errorQueue) head() error {
+	if len(q.pending) == 0 {
+		return nil
+	}
+	return q.pending[0]
+}
+
+func (q *errorQueue) pop() {
+	q.pending = q.pending[1:]
+}
+
+// flush sends the errors pending into errChannel unless ctx is done first, in which case ctx.Err() is reported instead.
+func (q *errorQueue) flush(ctx context.Context) {
+	for _, err := range q.pending {
+		if ctx.Err() != nil {
+			break
+		}
+		select {
+		case q.errChannel <- err:
+		case <-ctx.Done():
+		}
+	}
+	if ctx.Err() != nil {
+		reportDone(ctx, q.errChannel)
+	}
+	q.pending = nil
+}
+
+// reportDone sends ctx.Err() into errChannel unless there is an unread error in it already.
+func reportDone(ctx context.Context, errChannel chan<- error) {
+	select {
+	case errChannel <- ctx.Err():
+	default:
+	}
+}
+
+// load loads the object converted into JSON, either with the loader or as OrderedMap.
+func (o Options) load(data string) (any, error) {
+	if o.OrderedKeys {
+		value, err := o.decodeValue(data)
+		if err != nil {
+			return nil, err
+		}
+		return value.ordered(o.DuplicateKeys), nil
+	}
+	var res any
+	byteParsedString := []byte(data)
+	if err := parseString(o.Loader, &byteParsedString, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
 }
 
 func parseString(loader UnmarshalFunc, data *[]byte, v any) error {
@@ -88,6 +280,22 @@ func parseString(loader UnmarshalFunc, data *[]byte, v any) error {
 	return nil
 }
 
+// lexerInput returns the input the lexer gets, i.e. with unicode escape sequences decoded
+// and JSON.parse calls unwrapped, if the options tell so.
+func lexerInput(o Options, inputStr *string) (*string, error) {
+	if o.UnicodeEscape {
+		var err error
+		if inputStr, err = decodeUnicodeEscape(inputStr); err != nil {
+			return nil, err
+		}
+	}
+	if o.JSONParse {
+		unwrapped := chompjs.UnwrapJSONParse(*inputStr)
+		inputStr = &unwrapped
+	}
+	return inputStr, nil
+}
+
 func decodeUnicodeEscape(s *string) (*string, error) {
 	// quotes must be addef for strconv.Unquote to recogniz a string
 	quoted := `"` + *s + `"`
@@ -1,10 +1,16 @@
 package gompjs
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"math"
 	"reflect"
+	"runtime"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 var defaultLoader UnmarshalFunc = json.Unmarshal
@@ -731,17 +737,14 @@ func TestStandardValues(t *testing.T) {
 }
 
 func TestNaN(t *testing.T) {
-	// this doesn't work as expected
-	t.Skip("NaN values aren't supported by Go")
-	var parseNaN = tests{
-		{
-			name:    "NaN",
-			args:    args{inputStr: `{"A": NaN}`},
-			want:    map[string]any{"A": math.NaN()},
-			wantErr: false,
-		},
+	inputStr := `{"A": NaN}`
+	got, err := Parse(&inputStr, WithNonFinite(NonFiniteSentinel))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if a := ResolveNonFinite(got).(map[string]any)["A"]; !math.IsNaN(a.(float64)) {
+		t.Errorf("ResolveNonFinite(Parse()) = %v, want NaN", got)
 	}
-	runner(t, &parseNaN)
 }
 
 func TestStrangeValues(t *testing.T) {
@@ -936,3 +939,116 @@ func TestParseJsObjects2(t *testing.T) {
 		})
 	}
 }
+
+func TestParseJsObjectConcurrent(t *testing.T) {
+	var cases tests
+	for _, ut := range []tests{objectTests, listTests, mixedTests, standardValues, strangeValues, strangeInput, integetNumericValuesTests, floatNumericValuesTests, commentsTests, exceptionsTests} {
+		cases = append(cases, ut...)
+	}
+	const workers = 64
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(offset int) {
+			defer wg.Done()
+			for i := range cases {
+				tt := cases[(i+offset)%len(cases)]
+				inputStr := tt.args.inputStr
+				got, err := ParseJsObject(&inputStr, false, defaultLoader)
+				if (err != nil) != tt.wantErr {
+					t.Errorf("ParseJsObject(%q) error = %v, wantErr %v", tt.args.inputStr, err, tt.wantErr)
+					continue
+				}
+				if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("ParseJsObject(%q) = %v, want %v", tt.args.inputStr, got, tt.want)
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+}
+
+func TestParseJsObjectsConcurrent(t *testing.T) {
+	inputs := []struct {
+		inputStr string
+		want     []any
+	}{
+		{
+			inputStr: "[12] [13] [14]",
+			want:     []any{[]any{float64(12)}, []any{float64(13)}, []any{float64(14)}},
+		},
+		{
+			inputStr: "{am: 'ab'}\n{'ab': 'xx'}",
+			want:     []any{map[string]any{"am": "ab"}, map[string]any{"ab": "xx"}},
+		},
+		{
+			inputStr: "var a = {'a': [1, 2, 3]}; var b = [{b: 'c'}, 0x12]",
+			want: []any{
+				map[string]any{"a": []any{float64(1), float64(2), float64(3)}},
+				[]any{map[string]any{"b": "c"}, float64(18)},
+			},
+		},
+	}
+	const workers = 64
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(offset int) {
+			defer wg.Done()
+			for i := range inputs {
+				in := inputs[(i+offset)%len(inputs)]
+				inputStr := in.inputStr
+				dataChannel, _ := ParseJsObjects(&inputStr, false, false, defaultLoader)
+				var got []any
+				for data := range dataChannel {
+					got = append(got, data)
+				}
+				if !reflect.DeepEqual(got, in.want) {
+					t.Errorf("ParseJsObjects(%q) = %v, want %v", in.inputStr, got, in.want)
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+}
+
+func TestParseJsObjectsContext(t *testing.T) {
+	inputStr := strings.Repeat("{'a': [1, 2, 3]} ", 1000)
+	before := runtime.NumGoroutine()
+	ctx, cancel := context.WithCancel(context.Background())
+	dataChannel, errChannel := ParseJsObjectsContext(ctx, &inputStr, false, false, defaultLoader)
+	if got := <-dataChannel; !reflect.DeepEqual(got, map[string]any{"a": []any{float64(1), float64(2), float64(3)}}) {
+		t.Fatalf("ParseJsObjectsContext() = %v", got)
+	}
+	// the consumer stops reading here
+	cancel()
+	if err := <-errChannel; !errors.Is(err, context.Canceled) {
+		t.Errorf("ParseJsObjectsContext() error = %v, want %v", err, context.Canceled)
+	}
+	// both channels must be closed, the data channel may hold at most one element sent before the cancellation
+	for range dataChannel {
+	}
+	if _, ok := <-errChannel; ok {
+		t.Error("ParseJsObjectsContext() error channel isn't closed")
+	}
+	// the lexer goroutines must be gone
+	for i := 0; runtime.NumGoroutine() > before; i++ {
+		if i == 100 {
+			t.Fatalf("ParseJsObjectsContext() leaks goroutines: %d before, %d after", before, runtime.NumGoroutine())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestParseJsObjectsContextDone(t *testing.T) {
+	inputStr := "[1] [2] [3]"
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	dataChannel, errChannel := ParseJsObjectsContext(ctx, &inputStr, false, false, defaultLoader)
+	for data := range dataChannel {
+		t.Errorf("ParseJsObjectsContext() = %v, want nothing", data)
+	}
+	if err := <-errChannel; !errors.Is(err, context.Canceled) {
+		t.Errorf("ParseJsObjectsContext() error = %v, want %v", err, context.Canceled)
+	}
+}
@@ -0,0 +1,63 @@
+package gompjs
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/proway2/gompjs/internal/chompjs"
+)
+
+// ErrUnicodeEscapeReader is returned by ParseReader for WithUnicodeEscape, which needs the whole input at once.
+var ErrUnicodeEscapeReader = errors.New("unicode escape is not supported when reading from io.Reader")
+
+// ErrJSONParseReader is returned by ParseReader for WithJSONParse, which needs the whole input at once.
+var ErrJSONParseReader = errors.New("JSON.parse unwrapping is not supported when reading from io.Reader")
+
+// ParseJsObjectsReader is ParseReader with positional arguments.
+func ParseJsObjectsReader(r io.Reader, omitEmpty bool, loader UnmarshalFunc) (<-chan any, <-chan error) {
+	return ParseReader(context.Background(), r, positional(false, omitEmpty, loader))
+}
+
+// ParseJsObjectsReaderContext is ParseReader with positional arguments.
+func ParseJsObjectsReaderContext(ctx context.Context, r io.Reader, omitEmpty bool, loader UnmarshalFunc) (<-chan any, <-chan error) {
+	return ParseReader(ctx, r, positional(false, omitEmpty, loader))
+}
+
+// ParseReader is ParseAll reading its input from r in chunks instead of a string.
+// Objects are sent as soon as each one is closed, and the memory used is bounded by the size
+// of the largest object rather than by the size of the input.
+// An error returned by r, other than io.EOF, is sent into the error channel.
+//
+// WithUnicodeEscape and WithJSONParse can't be applied to a stream, ErrUnicodeEscapeReader
+// or ErrJSONParseReader is returned instead.
+func ParseReader(ctx context.Context, r io.Reader, opts ...Option) (<-chan any, <-chan error) {
+	o := newOptions(opts)
+	return streamReader(ctx, r, o, loadAny(o))
+}
+
+// streamReader runs chompjs over the input read from r and loads every object it finds with load.
+func streamReader[T any](ctx context.Context, r io.Reader, o Options, load loadFunc[T]) (<-chan T, <-chan error) {
+	dataChannel := make(chan T)
+	errChannel := make(chan error, 1)
+	if o.UnicodeEscape || o.JSONParse {
+		if o.UnicodeEscape {
+			errChannel <- ErrUnicodeEscapeReader
+		} else {
+			errChannel <- ErrJSONParseReader
+		}
+		close(errChannel)
+		close(dataChannel)
+		return dataChannel, errChannel
+	}
+	go func() {
+		// loadObjects closes the data channel
+		defer close(errChannel)
+		// stops the lexer whenever this goroutine returns
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		chompjsResCh, chompjsErrCh := chompjs.FixReaderContext(ctx, r, o.lexerOptions())
+		loadObjects(ctx, chompjsResCh, chompjsErrCh, load, dataChannel, errChannel)
+	}()
+	return dataChannel, errChannel
+}
@@ -0,0 +1,142 @@
+package gompjs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// collect reads both channels until they are closed.
+func collect(dataChannel <-chan any, errChannel <-chan error) ([]any, error) {
+	var got []any
+	var parseErr error
+	for dataChannel != nil || errChannel != nil {
+		select {
+		case data, ok := <-dataChannel:
+			if !ok {
+				dataChannel = nil
+				continue
+			}
+			got = append(got, data)
+		case err, ok := <-errChannel:
+			if !ok {
+				errChannel = nil
+				continue
+			}
+			parseErr = err
+		}
+	}
+	return got, parseErr
+}
+
+func TestParseJsObjectsReader(t *testing.T) {
+	inputs := []string{
+		"[\"Test\\nDrive\"]\n{\"Test\": \"Drive\"}",
+		"",
+		"aaaaaaaaaaaaaaaa",
+		"      {'a': 12}",
+		"[1, 2, 3, 4]xxxxxxxxxxxxxxxxxxxxxxxx",
+		"[12] [13] [14]",
+		"[10] {'a': [1, 1, 1,]}",
+		"[1] [2] {'a': ",
+		"[][][][]",
+		"{{}}{{}}",
+		"{am: 'ab'}\n{'ab': 'xx'}",
+		"function(a, b, c){ /* ... */ }({\"a\": 12}, Null, [1, 2, 3])",
+		"{\"a\": 12, broken}{\"c\": 100}",
+		"[12,,,,21][211,,,][12,12][12,,,21]",
+		"[true]x [false]_ [null]",
+		"var x = {a: 0x1F, b: 12_000, c: .5, d: -0b11} // {ignored: ']'}",
+		"{\"abc\": function() {return '])))))))))))))))';}} [/* ] */ 1]",
+		"<script>var data = {'hello': 'world', 'my': {'master': 'of Orion'}};</script> <p>[text]</p>",
+	}
+	readers := map[string]func(string) io.Reader{
+		"whole":    func(s string) io.Reader { return strings.NewReader(s) },
+		"one byte": func(s string) io.Reader { return iotest.OneByteReader(strings.NewReader(s)) },
+		"half":     func(s string) io.Reader { return iotest.HalfReader(strings.NewReader(s)) },
+	}
+	for _, inputStr := range inputs {
+		inputStr := inputStr
+		want, _ := collect(ParseJsObjects(&inputStr, false, false, defaultLoader))
+		for name, newReader := range readers {
+			got, err := collect(ParseJsObjectsReader(newReader(inputStr), false, defaultLoader))
+			if err != nil {
+				t.Errorf("ParseJsObjectsReader(%s, %q) error = %v", name, inputStr, err)
+				continue
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("ParseJsObjectsReader(%s, %q) = %v, want %v", name, inputStr, got, want)
+			}
+		}
+	}
+}
+
+func TestParseJsObjectsReaderLargeInput(t *testing.T) {
+	// objects are much larger than a single read, and the garbage between them too
+	object := "{'a': [" + strings.Repeat("'xxxxxxxxxx', ", 10000) + "'end']}"
+	garbage := strings.Repeat("<p>text</p>", 10000)
+	inputStr := strings.Repeat(object+garbage, 5) + "[1]"
+	got, err := collect(ParseJsObjectsReader(strings.NewReader(inputStr), false, defaultLoader))
+	if err != nil {
+		t.Fatalf("ParseJsObjectsReader() error = %v", err)
+	}
+	if len(got) != 6 {
+		t.Fatalf("ParseJsObjectsReader() returned %d objects, want 6", len(got))
+	}
+	for _, element := range got[:5] {
+		if items := element.(map[string]any)["a"].([]any); len(items) != 10001 || items[10000] != "end" {
+			t.Errorf("ParseJsObjectsReader() returned a broken object of %d items", len(items))
+		}
+	}
+	if !reflect.DeepEqual(got[5], []any{float64(1)}) {
+		t.Errorf("ParseJsObjectsReader() = %v, want [1]", got[5])
+	}
+}
+
+func TestParseJsObjectsReaderError(t *testing.T) {
+	readErr := errors.New("connection reset")
+	r := io.MultiReader(strings.NewReader("[1] [2] {'a': "), iotest.ErrReader(readErr))
+	got, err := collect(ParseJsObjectsReader(r, false, defaultLoader))
+	if !errors.Is(err, readErr) {
+		t.Errorf("ParseJsObjectsReader() error = %v, want %v", err, readErr)
+	}
+	if want := []any{[]any{float64(1)}, []any{float64(2)}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseJsObjectsReader() = %v, want %v", got, want)
+	}
+}
+
+func TestParseReaderMatchesParseAll(t *testing.T) {
+	// the input is read in chunks of 64 KiB, the padding moves their boundaries across the objects
+	text := strings.Repeat("x", 1000)
+	inputs := []string{
+		strings.Repeat("{f: -x} {a: '"+text+"'} ", 150),
+		strings.Repeat("[1, {b: )}] {c: ['"+text+"']} ", 150),
+		strings.Repeat("{d: 0x} [3, {e: '"+text+"'}] <p>x > y</p> ", 150),
+	}
+	modes := map[string][]Option{
+		"default":      nil,
+		"parse errors": {WithParseErrors()},
+	}
+	for name, opts := range modes {
+		opts := opts
+		t.Run(name, func(t *testing.T) {
+			for i, input := range inputs {
+				for _, padding := range []int{0, 1, 5, 13, 100, 400, 700, 1000} {
+					padded := strings.Repeat(" ", padding) + input
+					want, wantErrs := collectErrors(ParseAll(context.Background(), &padded, opts...))
+					got, errs := collectErrors(ParseReader(context.Background(), iotest.OneByteReader(strings.NewReader(padded)), opts...))
+					if len(got) != len(want) || !reflect.DeepEqual(got, want) {
+						t.Errorf("input %d padded with %d: ParseReader() = %d objects, ParseAll() = %d", i, padding, len(got), len(want))
+					}
+					if !reflect.DeepEqual(errs, wantErrs) {
+						t.Errorf("input %d padded with %d: ParseReader() errors = %d, ParseAll() errors = %d", i, padding, len(errs), len(wantErrs))
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package gompjs
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/proway2/gompjs/internal/chompjs"
+)
+
+// DecodeError reports an object which has been converted into JSON,
+// but which the loader can't load or decode into the requested type.
+type DecodeError struct {
+	// Raw is the object converted into JSON.
+	Raw string
+	// Offset is the byte offset of the object in the input.
+	Offset int
+	// Err is the error returned by the loader.
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("error decoding object at character %d: %v", e.Offset, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// ParseJsObjectAs is ParseAs with positional arguments.
+func ParseJsObjectAs[T any](inputStr *string, unicodeEscape bool, loader UnmarshalFunc) (T, error) {
+	return ParseAs[T](inputStr, positional(unicodeEscape, false, loader))
+}
+
+// ParseJsObjectsAs is ParseAllAs with positional arguments.
+func ParseJsObjectsAs[T any](inputStr *string, unicodeEscape, omitEmpty bool, loader UnmarshalFunc) (<-chan T, <-chan error) {
+	return ParseAllAs[T](context.Background(), inputStr, positional(unicodeEscape, omitEmpty, loader))
+}
+
+// ParseJsObjectsAsContext is ParseAllAs with positional arguments.
+func ParseJsObjectsAsContext[T any](ctx context.Context, inputStr *string, unicodeEscape, omitEmpty bool, loader UnmarshalFunc) (<-chan T, <-chan error) {
+	return ParseAllAs[T](ctx, inputStr, positional(unicodeEscape, omitEmpty, loader))
+}
+
+// ParseAs is Parse decoding the object straight into a value of type T with the loader,
+// without a round trip through any. A loader error is returned as *DecodeError.
+func ParseAs[T any](inputStr *string, opts ...Option) (T, error) {
+	o := newOptions(opts)
+	var res T
+	inputStr, err := lexerInput(o, inputStr)
+	if err != nil {
+		return res, err
+	}
+	var parsedString *string
+	if parsedString, err = chompjs.FixString(inputStr); err != nil {
+		return res, newParseError(err)
+	}
+	data, _, err := o.mergeKeys(*parsedString, o.keyOffsets(*inputStr, 0))
+	if err != nil {
+		return res, &DecodeError{Raw: *parsedString, Err: err}
+	}
+	if err = o.Loader([]byte(data), &res); err != nil {
+		var zero T
+		return zero, &DecodeError{Raw: *parsedString, Err: err}
+	}
+	return res, nil
+}
+
+// ParseAllAs is ParseAll decoding every object straight into a value of type T with the loader.
+//
+// Just as ParseAll does, it skips the objects the loader can't load at all, unless WithLoadErrors is set.
+// An object which is loaded fine, but can't be decoded into T, is reported as *DecodeError
+// in the error channel and the stream goes on, so both channels must be read until they are closed.
+func ParseAllAs[T any](ctx context.Context, inputStr *string, opts ...Option) (<-chan T, <-chan error) {
+	o := newOptions(opts)
+	return streamObjects(ctx, inputStr, o, loadAs[T](o))
+}
+
+// loadAs decodes elements into T, skipping the ones loadAny skips.
+func loadAs[T any](o Options) loadFunc[T] {
+	return func(object *chompjs.Object) (T, bool, error) {
+		var element T
+		// the lexer removes whitespaces, comments and trailing commas from empty objects
+		if o.OmitEmpty && (object.JSON == "{}" || object.JSON == "[]") {
+			return element, false, nil
+		}
+		data, _, err := o.mergeKeys(object.JSON, object.Keys)
+		if err != nil {
+			if !o.LoadErrors {
+				return element, false, nil
+			}
+			return element, false, &DecodeError{Raw: object.JSON, Offset: object.Start, Err: err}
+		}
+		byteParsedString := []byte(data)
+		if err := o.Loader(byteParsedString, &element); err != nil {
+			// tell objects which don't fit T from the ones which aren't valid at all
+			var probe any
+			if !o.LoadErrors && parseString(o.Loader, &byteParsedString, &probe) != nil {
+				return element, false, nil
+			}
+			var zero T
+			return zero, false, &DecodeError{Raw: object.JSON, Offset: object.Start, Err: err}
+		}
+		return element, true, nil
+	}
+}
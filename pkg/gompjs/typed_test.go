@@ -0,0 +1,83 @@
+package gompjs
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type product struct {
+	Name  string   `json:"name"`
+	Price float64  `json:"price"`
+	Tags  []string `json:"tags"`
+}
+
+func TestParseJsObjectAs(t *testing.T) {
+	inputStr := "var product = {name: 'Lamp', price: 12.5, tags: ['home', 'light',],}"
+	got, err := ParseJsObjectAs[product](&inputStr, false, defaultLoader)
+	if err != nil {
+		t.Fatalf("ParseJsObjectAs() error = %v", err)
+	}
+	if want := (product{Name: "Lamp", Price: 12.5, Tags: []string{"home", "light"}}); !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseJsObjectAs() = %+v, want %+v", got, want)
+	}
+
+	inputStr = "{name: 'Lamp', price: 'unknown'}"
+	_, err = ParseJsObjectAs[product](&inputStr, false, defaultLoader)
+	var decodeErr *DecodeError
+	if !errors.As(err, &decodeErr) {
+		t.Fatalf("ParseJsObjectAs() error = %v, want *DecodeError", err)
+	}
+	if want := `{"name":"Lamp","price":"unknown"}`; decodeErr.Raw != want {
+		t.Errorf("DecodeError.Raw = %s, want %s", decodeErr.Raw, want)
+	}
+	var typeErr *json.UnmarshalTypeError
+	if !errors.As(err, &typeErr) {
+		t.Errorf("ParseJsObjectAs() error = %v, want it to wrap *json.UnmarshalTypeError", err)
+	}
+}
+
+func TestParseJsObjectsAs(t *testing.T) {
+	inputStr := "[{name: 'Lamp', price: 12.5}] {} {name: 'Desk', price: 'n/a'} [12,,,21] {name: 'Chair', price: 40}"
+	calls := 0
+	loader := func(data []byte, v any) error {
+		calls++
+		return json.Unmarshal(data, v)
+	}
+	dataChannel, errChannel := ParseJsObjectsAs[product](&inputStr, false, true, loader)
+	var got []product
+	var errs []error
+	for dataChannel != nil || errChannel != nil {
+		select {
+		case data, ok := <-dataChannel:
+			if !ok {
+				dataChannel = nil
+				continue
+			}
+			got = append(got, data)
+		case err, ok := <-errChannel:
+			if !ok {
+				errChannel = nil
+				continue
+			}
+			errs = append(errs, err)
+		}
+	}
+	// the list doesn't fit product, the empty object is omitted and the broken list is skipped
+	if want := []product{{Name: "Chair", Price: 40}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseJsObjectsAs() = %+v, want %+v", got, want)
+	}
+	if len(errs) != 2 {
+		t.Fatalf("ParseJsObjectsAs() errors = %v, want 2 errors", errs)
+	}
+	for i, want := range []string{`[{"name":"Lamp","price":12.5}]`, `{"name":"Desk","price":"n/a"}`} {
+		var decodeErr *DecodeError
+		if !errors.As(errs[i], &decodeErr) || decodeErr.Raw != want {
+			t.Errorf("ParseJsObjectsAs() error = %v, want *DecodeError for %s", errs[i], want)
+		}
+	}
+	if calls == 0 {
+		t.Error("ParseJsObjectsAs() hasn't used the loader")
+	}
+}
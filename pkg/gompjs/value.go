@@ -0,0 +1,379 @@
+package gompjs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/proway2/gompjs/internal/chompjs"
+)
+
+// ErrKind is returned by the accessors of Value called on a value of another kind.
+var ErrKind = errors.New("value is of another kind")
+
+// Kind is the kind of a Value.
+type Kind int
+
+const (
+	// KindInvalid is the kind of a missing value, such as the one Get returns for a path which isn't found.
+	KindInvalid Kind = iota
+	KindNull
+	KindBool
+	KindNumber
+	KindString
+	KindArray
+	KindObject
+)
+
+var kindNames = [...]string{"invalid", "null", "bool", "number", "string", "array", "object"}
+
+func (k Kind) String() string {
+	if k < 0 || int(k) >= len(kindNames) {
+		return "kind(" + strconv.Itoa(int(k)) + ")"
+	}
+	return kindNames[k]
+}
+
+// Value is a JSON value decoded without reflection. Objects keep their keys in order, duplicate ones included,
+// and numbers keep their text, so big integers don't lose digits and NaN is a number too.
+// Infinity and -Infinity follow the NonFinite policy just as they do with the loader, they're strings by default.
+//
+// Accessors can be called on a nil Value, which is KindInvalid, so lookups can be chained.
+type Value struct {
+	kind Kind
+	// text is the value of a string, or the text of a number or a boolean.
+	text    string
+	items   []Value
+	members []Member
+}
+
+// Member is a member of an object.
+type Member struct {
+	Key   string
+	Value Value
+}
+
+// Kind returns the kind of v.
+func (v *Value) Kind() Kind {
+	if v == nil {
+		return KindInvalid
+	}
+	return v.kind
+}
+
+// Get returns the value found following path from v: strings are keys of objects and ints are indexes of arrays.
+// The last member of an object wins over the previous ones with the same key, just as JSON.parse does.
+// It returns nil if the path isn't found.
+func (v *Value) Get(path ...any) *Value {
+	for _, step := range path {
+		switch key := step.(type) {
+		case string:
+			if v.Kind() != KindObject {
+				return nil
+			}
+			var found *Value
+			for i := range v.members {
+				if v.members[i].Key == key {
+					found = &v.members[i].Value
+				}
+			}
+			v = found
+		case int:
+			if v.Kind() != KindArray || key < 0 || key >= len(v.items) {
+				return nil
+			}
+			v = &v.items[key]
+		default:
+			return nil
+		}
+		if v == nil {
+			return nil
+		}
+	}
+	return v
+}
+
+// Len returns the number of elements of an array or members of an object, zero for other values.
+func (v *Value) Len() int {
+	switch v.Kind() {
+	case KindArray:
+		return len(v.items)
+	case KindObject:
+		return len(v.members)
+	}
+	return 0
+}
+
+// Items returns the elements of an array, nil for other values.
+func (v *Value) Items() []Value {
+	if v.Kind() != KindArray {
+		return nil
+	}
+	return v.items
+}
+
+// Members returns the members of an object in order, nil for other values.
+func (v *Value) Members() []Member {
+	if v.Kind() != KindObject {
+		return nil
+	}
+	return v.members
+}
+
+// IsNull tells whether v is null.
+func (v *Value) IsNull() bool {
+	return v.Kind() == KindNull
+}
+
+// Bool returns the value of a boolean.
+func (v *Value) Bool() (bool, error) {
+	if v.Kind() != KindBool {
+		return false, v.kindError(KindBool)
+	}
+	return v.text == "true", nil
+}
+
+// Int64 returns the value of a number which is an integer fitting in int64, such as 12, 1.0 or 1e3.
+func (v *Value) Int64() (int64, error) {
+	if v.Kind() != KindNumber {
+		return 0, v.kindError(KindNumber)
+	}
+	n, err := strconv.ParseInt(v.text, 10, 64)
+	if err == nil {
+		return n, nil
+	}
+	f, ferr := strconv.ParseFloat(v.text, 64)
+	if ferr != nil || f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+		return 0, fmt.Errorf("number %s is not an int64", v.text)
+	}
+	return int64(f), nil
+}
+
+// Float64 returns the value of a number, NaN, Infinity and -Infinity included.
+func (v *Value) Float64() (float64, error) {
+	if v.Kind() != KindNumber {
+		return 0, v.kindError(KindNumber)
+	}
+	f, err := strconv.ParseFloat(v.text, 64)
+	if err != nil && !errors.Is(err, strconv.ErrRange) {
+		return 0, err
+	}
+	return f, nil
+}
+
+// Text returns the value of a string.
+func (v *Value) Text() (string, error) {
+	if v.Kind() != KindString {
+		return "", v.kindError(KindString)
+	}
+	return v.text, nil
+}
+
+// String returns the value of a string, the text of a number and the JSON text of any other value.
+// Non-finite numbers nested in arrays and objects are written as they are, NaN, Infinity and -Infinity.
+func (v *Value) String() string {
+	switch v.Kind() {
+	case KindInvalid:
+		return ""
+	case KindString, KindNumber:
+		return v.text
+	}
+	var b strings.Builder
+	// only non-finite numbers fail, which are allowed
+	_ = v.write(&b, true)
+	return b.String()
+}
+
+// MarshalJSON returns the JSON text of v, with the keys of objects in order.
+// JSON has no non-finite numbers, they fail with *NonFiniteError.
+func (v *Value) MarshalJSON() ([]byte, error) {
+	if v.Kind() == KindInvalid {
+		return nil, fmt.Errorf("%w: invalid value", ErrKind)
+	}
+	var b strings.Builder
+	if err := v.write(&b, false); err != nil {
+		return nil, err
+	}
+	return []byte(b.String()), nil
+}
+
+// write writes the JSON text of v into b, a non-finite number fails with *NonFiniteError unless nonFinite is set.
+func (v *Value) write(b *strings.Builder, nonFinite bool) error {
+	switch v.kind {
+	case KindNull:
+		b.WriteString("null")
+	case KindBool:
+		b.WriteString(v.text)
+	case KindNumber:
+		if !nonFinite && isNonFinite(v.text) {
+			return &NonFiniteError{Value: v.text}
+		}
+		b.WriteString(v.text)
+	case KindString:
+		writeQuoted(b, v.text)
+	case KindArray:
+		b.WriteByte('[')
+		for i := range v.items {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			if err := v.items[i].write(b, nonFinite); err != nil {
+				return err
+			}
+		}
+		b.WriteByte(']')
+	case KindObject:
+		b.WriteByte('{')
+		for i := range v.members {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			writeQuoted(b, v.members[i].Key)
+			b.WriteByte(':')
+			if err := v.members[i].Value.write(b, nonFinite); err != nil {
+				return err
+			}
+		}
+		b.WriteByte('}')
+	}
+	return nil
+}
+
+// isNonFinite tells whether the text of a number is NaN, Infinity or -Infinity.
+func isNonFinite(text string) bool {
+	return text == "NaN" || text == "Infinity" || text == "-Infinity"
+}
+
+// writeQuoted writes s into b as a JSON string, escaping quotes, backslashes and control characters.
+// Invalid UTF-8 is replaced with U+FFFD, just as encoding/json does.
+func writeQuoted(b *strings.Builder, s string) {
+	const hex = "0123456789abcdef"
+	b.WriteByte('"')
+	for _, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r < 0x20:
+			b.WriteString(`\u00`)
+			b.WriteByte(hex[r>>4])
+			b.WriteByte(hex[r&0xf])
+		default:
+			// ranging over s turns invalid UTF-8 into utf8.RuneError already
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+}
+
+// Any returns v the way encoding/json loads it into any: map[string]any, []any, string, float64, bool or nil.
+func (v *Value) Any() any {
+	switch v.Kind() {
+	case KindBool:
+		return v.text == "true"
+	case KindNumber:
+		f, _ := v.Float64()
+		return f
+	case KindString:
+		return v.text
+	case KindArray:
+		items := make([]any, len(v.items))
+		for i := range v.items {
+			items[i] = v.items[i].Any()
+		}
+		return items
+	case KindObject:
+		members := make(map[string]any, len(v.members))
+		for i := range v.members {
+			members[v.members[i].Key] = v.members[i].Value.Any()
+		}
+		return members
+	}
+	return nil
+}
+
+func (v *Value) kindError(want Kind) error {
+	return fmt.Errorf("%w: %s is not a %s", ErrKind, v.Kind(), want)
+}
+
+// ParseValue is Parse decoding the object into a Value with the built-in decoder instead of the loader.
+func ParseValue(inputStr *string, opts ...Option) (*Value, error) {
+	o := newOptions(opts)
+	inputStr, err := lexerInput(o, inputStr)
+	if err != nil {
+		return nil, err
+	}
+	var parsedString *string
+	if parsedString, err = chompjs.FixString(inputStr); err != nil {
+		return nil, newParseError(err)
+	}
+	data, _, err := o.mergeKeys(*parsedString, o.keyOffsets(*inputStr, 0))
+	if err != nil {
+		return nil, err
+	}
+	value, err := o.decodeValue(data)
+	if err != nil {
+		return nil, &DecodeError{Raw: *parsedString, Err: err}
+	}
+	return value, nil
+}
+
+// ParseAllValues is ParseAll decoding every object into a Value with the built-in decoder instead of the loader.
+func ParseAllValues(ctx context.Context, inputStr *string, opts ...Option) (<-chan *Value, <-chan error) {
+	o := newOptions(opts)
+	return streamObjects(ctx, inputStr, o, loadValue(o))
+}
+
+// ParseReaderValues is ParseReader decoding every object into a Value with the built-in decoder instead of the loader.
+func ParseReaderValues(ctx context.Context, r io.Reader, opts ...Option) (<-chan *Value, <-chan error) {
+	o := newOptions(opts)
+	return streamReader(ctx, r, o, loadValue(o))
+}
+
+// loadValue decodes elements into values, skipping the ones loadAny skips.
+func loadValue(o Options) loadFunc[*Value] {
+	return func(object *chompjs.Object) (*Value, bool, error) {
+		data, _, err := o.mergeKeys(object.JSON, object.Keys)
+		var value *Value
+		if err == nil {
+			value, err = o.decodeValue(data)
+		}
+		if err != nil {
+			if o.LoadErrors {
+				return nil, false, &DecodeError{Raw: object.JSON, Offset: object.Start, Err: err}
+			}
+			return nil, false, nil
+		}
+		if o.OmitEmpty && value.Len() == 0 && (value.kind == KindArray || value.kind == KindObject) {
+			return nil, false, nil
+		}
+		return value, true, nil
+	}
+}
+
+// decodeValue decodes the JSON text the lexer outputs following the options.
+// Non-finite numbers follow the NonFinite policy just as they do with the loader,
+// so by default NaN is a number while Infinity and -Infinity are strings.
+func (o Options) decodeValue(data string) (*Value, error) {
+	if o.NonFinite == NonFiniteKeep && !o.BigIntStrings && !strings.Contains(data, "Infinity") {
+		return decodeValue(data)
+	}
+	converted, err := o.NonFinite.convert([]byte(data))
+	if err != nil {
+		return nil, err
+	}
+	if o.BigIntStrings {
+		converted = quoteBigInts(converted)
+	}
+	return decodeValue(string(converted))
+}
@@ -0,0 +1,197 @@
+package gompjs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseValue(t *testing.T) {
+	input := `var data = {z: 1, a: [true, null, {b: 'x\'y', c: "\x41é"}], id: 0xFFFFFFFFFFFFFFFF,
+		n: -1.5e3, big: 123n, nan: NaN, inf: -Infinity, a: 'last'}`
+	v, err := ParseValue(&input)
+	if err != nil {
+		t.Fatalf("ParseValue() error = %v", err)
+	}
+	var keys []string
+	for _, member := range v.Members() {
+		keys = append(keys, member.Key)
+	}
+	if want := []string{"z", "a", "id", "n", "big", "nan", "inf", "a"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("Members() keys = %v, want %v", keys, want)
+	}
+	if got := v.Get("a").String(); got != "last" {
+		t.Errorf(`Get("a") = %q, want the last member "last"`, got)
+	}
+	first := v.Members()[1].Value
+	if got, err := first.Get(2, "b").Text(); err != nil || got != "x'y" {
+		t.Errorf(`Get(2, "b").Text() = %q, %v, want "x'y"`, got, err)
+	}
+	if got := first.Get(2, "c").String(); got != "Aé" {
+		t.Errorf(`Get(2, "c").String() = %q, want "Aé"`, got)
+	}
+	if got, err := first.Get(0).Bool(); err != nil || !got {
+		t.Errorf("Get(0).Bool() = %v, %v, want true", got, err)
+	}
+	if !first.Get(1).IsNull() {
+		t.Errorf("Get(1) = %v, want null", first.Get(1))
+	}
+	if got := v.Get("id").String(); got != "18446744073709551615" {
+		t.Errorf(`Get("id").String() = %q, want all the digits`, got)
+	}
+	if _, err := v.Get("id").Int64(); err == nil {
+		t.Errorf(`Get("id").Int64() error = nil, want out of range`)
+	}
+	if got, err := v.Get("n").Int64(); err != nil || got != -1500 {
+		t.Errorf(`Get("n").Int64() = %d, %v, want -1500`, got, err)
+	}
+	if got, err := v.Get("big").Int64(); err != nil || got != 123 {
+		t.Errorf(`Get("big").Int64() = %d, %v, want 123`, got, err)
+	}
+	if got, err := v.Get("nan").Float64(); err != nil || !math.IsNaN(got) {
+		t.Errorf(`Get("nan").Float64() = %v, %v, want NaN`, got, err)
+	}
+	// just as with the loader, Infinity and -Infinity are strings by default
+	if got, err := v.Get("inf").Text(); err != nil || got != "-Infinity" {
+		t.Errorf(`Get("inf").Text() = %q, %v, want "-Infinity"`, got, err)
+	}
+	if got := v.Get("missing", 0, "x"); got != nil || got.Kind() != KindInvalid {
+		t.Errorf(`Get("missing", 0, "x") = %v, want nil`, got)
+	}
+	if _, err := v.Get("z").Text(); !errors.Is(err, ErrKind) {
+		t.Errorf(`Get("z").Text() error = %v, want %v`, err, ErrKind)
+	}
+}
+
+func TestValueAny(t *testing.T) {
+	input := `{"a": [1, "b", null, false, {"c": 2.5}], "d": {}}`
+	v, err := ParseValue(&input)
+	if err != nil {
+		t.Fatalf("ParseValue() error = %v", err)
+	}
+	var want any
+	if err := json.Unmarshal([]byte(input), &want); err != nil {
+		t.Fatal(err)
+	}
+	if got := v.Any(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Any() = %v, want %v", got, want)
+	}
+	marshaled, err := json.Marshal(v)
+	if err != nil || string(marshaled) != `{"a":[1,"b",null,false,{"c":2.5}],"d":{}}` {
+		t.Errorf("json.Marshal() = %s, %v", marshaled, err)
+	}
+}
+
+func TestValueMarshalJSON(t *testing.T) {
+	input := `{'a\x01\tb': ['\x07\v\x1f', 'q"\\', '\u2028é']}`
+	v, err := ParseValue(&input)
+	if err != nil {
+		t.Fatalf("ParseValue() error = %v", err)
+	}
+	marshaled, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	if !json.Valid(marshaled) {
+		t.Fatalf("json.Marshal() = %s, not valid JSON", marshaled)
+	}
+	var got any
+	if err := json.Unmarshal(marshaled, &got); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	want := map[string]any{"a\x01\tb": []any{"\a\v\x1f", `q"\`, "\u2028é"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("round trip = %q, want %q", got, want)
+	}
+	if !reflect.DeepEqual(got, v.Any()) {
+		t.Errorf("round trip = %q, want Any() %q", got, v.Any())
+	}
+
+	input = `{a: [1, NaN]}`
+	if v, err = ParseValue(&input); err != nil {
+		t.Fatalf("ParseValue() error = %v", err)
+	}
+	var nonFinite *NonFiniteError
+	if _, err := json.Marshal(v); !errors.As(err, &nonFinite) || nonFinite.Value != "NaN" {
+		t.Errorf("json.Marshal() error = %v, want *NonFiniteError", err)
+	}
+	if got := v.String(); got != `{"a":[1,NaN]}` {
+		t.Errorf("String() = %s, want NaN written as it is", got)
+	}
+}
+
+func TestDecodeValueErrors(t *testing.T) {
+	for _, input := range []string{``, `{`, `{"a" 1}`, `[1,]`, `[1 2]`, `"abc`, `tru`, `{"a":1}x`, `[-]`,
+		`{"a": 1-2}`, `[1.2.3]`, `[--]`, `[01]`, `[1.]`, `[1e]`, `[1e+]`, `[-.5]`} {
+		if _, err := DecodeValue([]byte(input)); !errors.Is(err, ErrSyntax) {
+			t.Errorf("DecodeValue(%q) error = %v, want %v", input, err, ErrSyntax)
+		}
+	}
+}
+
+func TestParseValueInvalidNumber(t *testing.T) {
+	input := `{a: 1, b: 1-2, c: 1.2.3}`
+	_, err := ParseValue(&input)
+	var decodeErr *DecodeError
+	if !errors.As(err, &decodeErr) || !errors.Is(err, ErrSyntax) {
+		t.Fatalf("ParseValue() error = %v, want *DecodeError of %v", err, ErrSyntax)
+	}
+	// the offset refers to the JSON text of the object, decodeErr.Raw
+	if want := `invalid character '-' at offset 12`; !strings.Contains(err.Error(), want) {
+		t.Errorf("ParseValue() error = %v, want %q", err, want)
+	}
+}
+
+func TestParseAllValues(t *testing.T) {
+	input := `[1] {} {a: 0xFF, b: 9007199254740993}`
+	var got []string
+	dataChannel, errChannel := ParseAllValues(context.Background(), &input, WithOmitEmpty(), WithBigIntStrings())
+	for v := range dataChannel {
+		got = append(got, v.String())
+	}
+	if err := <-errChannel; err != nil {
+		t.Errorf("ParseAllValues() error = %v", err)
+	}
+	if want := []string{"[1]", `{"a":255,"b":"9007199254740993"}`}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseAllValues() = %v, want %v", got, want)
+	}
+}
+
+// benchmarkInput is a page state made of many small objects, such as the ones found in scripts.
+var benchmarkInput = func() string {
+	var b strings.Builder
+	b.WriteString(`{"products": [`)
+	for i := 0; i < 500; i++ {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(`{"id": 1234567, "name": "Lamp \"Classic\"", "price": 12.5, "tags": ["new", "sale"], "available": true, "brand": {"id": 7, "name": "Acme"}, "rating": null}`)
+	}
+	b.WriteString(`]}`)
+	return b.String()
+}()
+
+func BenchmarkDecodeValue(b *testing.B) {
+	data := []byte(benchmarkInput)
+	b.SetBytes(int64(len(data)))
+	for i := 0; i < b.N; i++ {
+		if _, err := DecodeValue(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkJSONUnmarshal(b *testing.B) {
+	data := []byte(benchmarkInput)
+	b.SetBytes(int64(len(data)))
+	for i := 0; i < b.N; i++ {
+		var v any
+		if err := json.Unmarshal(data, &v); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
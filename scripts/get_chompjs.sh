@@ -1,5 +1,13 @@
 #!/bin/sh
 
+# The C lexer is a fork of chompjs: the patches of scripts/chompjs are applied, in order, to the upstream files.
+# lexer_purego.go ports the patched lexer, so a change to the lexer goes both into a new patch and into the port.
+#
+# 0001-non-finite-numbers.patch    Infinity and -Infinity values (WithNonFinite)
+# 0002-non-decimal-numbers.patch   hexadecimal, octal and binary integers and BigInt literals, converted losslessly
+
+set -e
+
 CURL=`which curl`
 
 COMMIT_HASH="97dea8436c7ca680a11ce558f70597fc7621e17f"
@@ -11,7 +19,17 @@ PARSER_C="$BASE_URL/parser.c"
 PARSER_H="$BASE_URL/parser.h"
 
 PATH_TO_CHOMPJS="./internal/chompjs"
-$CURL -s -o $PATH_TO_CHOMPJS/buffer.c $BUFFER_C
-$CURL -s -o $PATH_TO_CHOMPJS/buffer.h $BUFFER_H
-$CURL -s -o $PATH_TO_CHOMPJS/parser.c $PARSER_C
-$CURL -s -o $PATH_TO_CHOMPJS/parser.h $PARSER_H
+PATH_TO_PATCHES="./scripts/chompjs"
+$CURL -sf -o $PATH_TO_CHOMPJS/buffer.c $BUFFER_C
+$CURL -sf -o $PATH_TO_CHOMPJS/buffer.h $BUFFER_H
+$CURL -sf -o $PATH_TO_CHOMPJS/parser.c $PARSER_C
+$CURL -sf -o $PATH_TO_CHOMPJS/parser.h $PARSER_H
+
+for PATCH in $PATH_TO_PATCHES/*.patch; do
+    patch -s -p1 < $PATCH
+done
+
+# the C lexer is only built with cgo, otherwise the pure Go port (lexer_purego.go) is used
+for C_FILE in $PATH_TO_CHOMPJS/buffer.c $PATH_TO_CHOMPJS/parser.c; do
+    sed -i '1i //go:build cgo \&\& !purego\n' $C_FILE
+done